@@ -0,0 +1,163 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"regexp"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+const (
+	// CallerNameLegacy uses text after the last '.' of function name. closure yields func1
+	CallerNameLegacy CallerNameMode = iota
+	// CallerNameFunction uses name of enclosing function or method. closure and generic type parameters are resolved
+	CallerNameFunction
+	// CallerNameTypeDotMethod uses Type.Method for method, function name otherwise
+	CallerNameTypeDotMethod
+	// CallerNameTypeUnderscoreMethod uses Type_Method for method, function name otherwise
+	CallerNameTypeUnderscoreMethod
+)
+
+type CallerNameMode uint8
+
+func (c CallerNameMode) String() string {
+	switch c {
+	case CallerNameLegacy:
+		return "LEGACY"
+	case CallerNameFunction:
+		return "FUNCTION"
+	case CallerNameTypeDotMethod:
+		return "TYPE_DOT_METHOD"
+	case CallerNameTypeUnderscoreMethod:
+		return "TYPE_UNDERSCORE_METHOD"
+	}
+	return "UNKNOWN"
+}
+
+// callerId returns statement id for caller pc. resolved id is cached per call site
+func (man *StringMan) callerId(pc uintptr) string {
+	if id, ok := man.callerCache.Load(pc); ok {
+		return id.(string)
+	}
+
+	var id string
+	if man.preference.CallerNameMode == CallerNameLegacy {
+		id = findFunctionName(pc)
+	} else {
+		id = resolveFunctionName(runtime.FuncForPC(pc).Name(), man.preference.CallerNameMode)
+	}
+
+	man.callerCache.Store(pc, id)
+	return id
+}
+
+const genericTypeParams = "[...]"
+
+var closureSegmentRegex = regexp.MustCompile(`^(func|gowrap|deferwrap)?[0-9]+$`)
+
+// resolveFunctionName resolves runtime function name to statement id.
+// e.g) github.com/x/pkg.(*Repo[...]).Insert.func1.2 => Insert, Repo.Insert or Repo_Insert
+func resolveFunctionName(funcName string, mode CallerNameMode) string {
+	name := funcName
+	slash := strings.LastIndexByte(name, '/')
+	if slash >= 0 {
+		name = name[slash+1:]
+	}
+
+	// type parameters of generic function are shown as [...]
+	name = strings.ReplaceAll(name, genericTypeParams, "")
+
+	segments := strings.Split(name, ".")
+	if len(segments) > 1 {
+		segments = segments[packageSegmentCount(segments, slash >= 0):]
+	}
+	for i, s := range segments {
+		s = strings.TrimSuffix(s, "-fm")
+		segments[i] = strings.TrimSuffix(strings.TrimPrefix(s, "(*"), ")")
+	}
+
+	// walk to enclosing function of closure
+	segments = trimClosureSegments(segments)
+
+	method := segments[len(segments)-1]
+	if len(segments) < 2 {
+		return method
+	}
+
+	typeName := segments[len(segments)-2]
+	switch mode {
+	case CallerNameTypeDotMethod:
+		return typeName + "." + method
+	case CallerNameTypeUnderscoreMethod:
+		return typeName + "_" + method
+	}
+	return method
+}
+
+const mainPackage = "main"
+
+// packageSegmentCount returns the number of leading segments for package.
+// runtime escapes '.' in last element of package path (gopkg.in/yaml%2ev3) so package is the first segment
+// after '/'. package path without '/' like example.com is found from function side instead :
+// segment before function is receiver when it is (*T) or exported type, otherwise it belongs to package
+func packageSegmentCount(segments []string, hasSlash bool) int {
+	if hasSlash || segments[0] == mainPackage {
+		return 1
+	}
+
+	trimmed := make([]string, len(segments))
+	for i, s := range segments {
+		trimmed[i] = strings.TrimSuffix(s, "-fm")
+	}
+	function := len(trimClosureSegments(trimmed[1:])) // index of function segment in segments
+	if function < 2 {
+		return 1
+	}
+
+	receiver := segments[function-1]
+	if strings.HasPrefix(receiver, "(*") || (len(receiver) > 0 && unicode.IsUpper([]rune(receiver)[0])) {
+		return function - 1
+	}
+	return function
+}
+
+func trimClosureSegments(segments []string) []string {
+	for len(segments) > 1 && closureSegmentRegex.MatchString(segments[len(segments)-1]) {
+		segments = segments[:len(segments)-1]
+	}
+	for len(segments) > 1 && len(segments[len(segments)-1]) == 0 {
+		segments = segments[:len(segments)-1]
+	}
+	return segments
+}
+
+func findFunctionName(pc uintptr) string {
+	var funcName = runtime.FuncForPC(pc).Name()
+	var found = strings.LastIndexByte(funcName, '.')
+	if found < 0 {
+		return funcName
+	}
+	return funcName[found+1:]
+}
@@ -0,0 +1,91 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveFunctionName(t *testing.T) {
+	cases := []struct {
+		funcName string
+		mode     CallerNameMode
+		expect   string
+	}{
+		{"github.com/x/pkg.SelectCity", CallerNameFunction, "SelectCity"},
+		{"github.com/x/pkg.SelectCity.func1", CallerNameFunction, "SelectCity"},
+		{"github.com/x/pkg.SelectCity.func1.2", CallerNameFunction, "SelectCity"},
+		{"github.com/x/pkg.SelectCity.gowrap1", CallerNameFunction, "SelectCity"},
+		{"github.com/x/pkg.SelectCity[...]", CallerNameFunction, "SelectCity"},
+		{"github.com/x/pkg.(*CityRepo).SelectCity", CallerNameFunction, "SelectCity"},
+		{"github.com/x/pkg.(*CityRepo).SelectCity", CallerNameTypeDotMethod, "CityRepo.SelectCity"},
+		{"github.com/x/pkg.CityRepo.SelectCity.func1", CallerNameTypeUnderscoreMethod, "CityRepo_SelectCity"},
+		{"github.com/x/pkg.(*CityRepo[...]).SelectCity-fm", CallerNameTypeDotMethod, "CityRepo.SelectCity"},
+		{"gopkg.in/yaml%2ev3.SelectCity.func2", CallerNameTypeDotMethod, "SelectCity"},
+		{"main.SelectCity", CallerNameFunction, "SelectCity"},
+		{"main.cityRepo.SelectCity", CallerNameTypeDotMethod, "cityRepo.SelectCity"},
+		{"example.com.SelectCity", CallerNameTypeDotMethod, "SelectCity"},
+		{"example.com.SelectCity.func1", CallerNameTypeDotMethod, "SelectCity"},
+		{"example.com.CityRepo.SelectCity", CallerNameTypeDotMethod, "CityRepo.SelectCity"},
+		{"example.com.(*cityRepo).SelectCity-fm", CallerNameTypeUnderscoreMethod, "cityRepo_SelectCity"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expect, resolveFunctionName(c.funcName, c.mode), c.funcName)
+	}
+}
+
+type cityRepo struct {
+	man *StringMan
+}
+
+func (r *cityRepo) selectCityWithName() (string, error) {
+	var built string
+	var err error
+	func() {
+		built, err = r.man.Build(BuildParam{"Name": "seoul"})
+	}()
+	return built, err
+}
+
+func TestBuildFromClosure(t *testing.T) {
+	pref := NewStringmanPreference(filepath.Dir(xmlFile))
+	pref.Fileset = xmlFilePrefix + "*.xml"
+	pref.CallerNameMode = CallerNameFunction
+	man, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	repo := &cityRepo{man: man}
+	for i := 0; i < 2; i++ {
+		built, err := repo.selectCityWithName()
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, "SELECT * FROM CITY WHERE NAME like 'seoul'", built)
+	}
+}
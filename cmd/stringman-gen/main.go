@@ -0,0 +1,368 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+// stringman-gen generates typed Go functions for statements in xml files.
+// so renaming or removing statement becomes compile error instead of runtime error
+//
+//	stringman-gen -path ./resources -fileset "string*.xml" -package texts -out texts_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fatima-go/stringman"
+)
+
+func main() {
+	path := flag.String("path", ".", "directory of xml files")
+	fileset := flag.String("fileset", "string*.xml", "glob pattern of xml files")
+	pkg := flag.String("package", "texts", "package name of generated file")
+	out := flag.String("out", "", "output file. stdout if empty")
+	named := flag.String("named", "", "comma separated id patterns of message statements generated with FormatNamed. e.g) Notify*")
+	flag.Parse()
+
+	pref := stringman.NewStringmanPreference(*path)
+	pref.Fileset = *fileset
+	man, err := stringman.NewStringman(pref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	src, err := generate(man.Statements(), *pkg, splitPatterns(*named))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fail to generate : %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if len(*out) == 0 {
+		os.Stdout.Write(src)
+		return
+	}
+
+	err = os.WriteFile(*out, src, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fail to write %s : %s\n", *out, err.Error())
+		os.Exit(1)
+	}
+}
+
+const (
+	idConstPrefix = "Id"
+	paramVarName  = "p"
+	managerName   = "man"
+	optionalName  = "optional"
+	argsName      = "args"
+	dataName      = "data"
+)
+
+// splitPatterns returns id patterns of comma separated flag value
+func splitPatterns(value string) []string {
+	patterns := make([]string, 0)
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if len(v) > 0 {
+			patterns = append(patterns, v)
+		}
+	}
+	return patterns
+}
+
+func generate(stmtList []stringman.QueryStatement, pkg string, named []string) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("// Code generated by stringman-gen. DO NOT EDIT.\n\n")
+	buffer.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+
+	var body bytes.Buffer
+	names := make(map[string]string)
+	body.WriteString("const (\n")
+	for _, stmt := range stmtList {
+		funcName := exportedName(stmt.Id)
+		constName := idConstPrefix + funcName
+		for _, n := range []string{funcName, constName} {
+			if prev, exists := names[n]; exists {
+				return nil, fmt.Errorf("generated name %s conflicts : %s, %s", n, prev, stmt.Id)
+			}
+			names[n] = stmt.Id
+		}
+		body.WriteString(fmt.Sprintf("\t%s = %q\n", constName, stmt.Id))
+	}
+	body.WriteString(")\n")
+
+	useTime := false
+	for _, stmt := range stmtList {
+		message, err := isMessage(stmt, named)
+		if err != nil {
+			return nil, err
+		}
+		if writeFunction(&body, stmt, message) {
+			useTime = true
+		}
+	}
+
+	if useTime {
+		buffer.WriteString("import (\n\t\"time\"\n\n\t\"github.com/fatima-go/stringman\"\n)\n\n")
+	} else {
+		buffer.WriteString("import \"github.com/fatima-go/stringman\"\n\n")
+	}
+	buffer.Write(body.Bytes())
+
+	return format.Source(buffer.Bytes())
+}
+
+// isMessage returns true when statement is completed as plain text by FormatNamed instead of Build.
+// statement with plural or select argument is always message
+func isMessage(stmt stringman.QueryStatement, named []string) (bool, error) {
+	for _, c := range stmt.Columns() {
+		if len(c.ChoiceKind()) > 0 {
+			return true, nil
+		}
+	}
+
+	for _, pattern := range named {
+		matched, err := path.Match(pattern, stmt.Id)
+		if err != nil {
+			return false, fmt.Errorf("invalid named pattern %s : %s", pattern, err.Error())
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// writeFunction writes function of statement and returns true when time package is used.
+// message statement is completed by FormatNamed so values are not sql quoted
+func writeFunction(buffer *bytes.Buffer, stmt stringman.QueryStatement, message bool) bool {
+	funcName := exportedName(stmt.Id)
+	constName := idConstPrefix + funcName
+	columns := stmt.Columns()
+
+	buffer.WriteString("\n")
+	if stmt.IsTemplate() {
+		buffer.WriteString(fmt.Sprintf("// %s renders %s template statement\n", funcName, stmt.Id))
+		buffer.WriteString(fmt.Sprintf("func %s(%s *stringman.StringMan, %s interface{}) (string, error) {\n",
+			funcName, managerName, dataName))
+		buffer.WriteString(fmt.Sprintf("\treturn %s.Render(%s, %s)\n}\n", managerName, constName, dataName))
+		return false
+	}
+
+	if len(columns) == 0 {
+		buffer.WriteString(fmt.Sprintf("// %s formats %s statement\n", funcName, stmt.Id))
+		buffer.WriteString(fmt.Sprintf("func %s(%s *stringman.StringMan, %s ...interface{}) (string, error) {\n",
+			funcName, managerName, argsName))
+		buffer.WriteString(fmt.Sprintf("\treturn %s.FormatWithStmt(%s, %s...)\n}\n", managerName, constName, argsName))
+		return false
+	}
+
+	// parameter of nested path like {User.Name} is passed by its root (User)
+	required := make([]string, 0)
+	requiredRoot := make(map[string]bool)
+	for _, c := range columns {
+		if c.IsRequired() && !requiredRoot[c.RootName()] {
+			requiredRoot[c.RootName()] = true
+			required = append(required, c.RootName())
+		}
+	}
+	hasOptional := false
+	for _, c := range columns {
+		if !requiredRoot[c.RootName()] {
+			hasOptional = true
+		}
+	}
+
+	args := []string{fmt.Sprintf("%s *stringman.StringMan", managerName)}
+	argNames := make(map[string]bool)
+	varNames := make([]string, len(required))
+	useTime := false
+	for i, c := range required {
+		varNames[i] = argumentName(c, argNames)
+		argType := argumentType(c, columns)
+		if argType == timeType {
+			useTime = true
+		}
+		args = append(args, fmt.Sprintf("%s %s", varNames[i], argType))
+	}
+	if hasOptional {
+		args = append(args, fmt.Sprintf("%s stringman.BuildParam", optionalName))
+	}
+
+	if message {
+		buffer.WriteString(fmt.Sprintf("// %s formats %s message\n", funcName, stmt.Id))
+	} else {
+		buffer.WriteString(fmt.Sprintf("// %s builds %s statement\n", funcName, stmt.Id))
+	}
+	buffer.WriteString(fmt.Sprintf("func %s(%s) (string, error) {\n", funcName, strings.Join(args, ", ")))
+	buffer.WriteString(fmt.Sprintf("\t%s := stringman.BuildParam{}\n", paramVarName))
+	if hasOptional {
+		buffer.WriteString(fmt.Sprintf("\tfor k, v := range %s {\n\t\t%s[k] = v\n\t}\n", optionalName, paramVarName))
+	}
+	for i, c := range required {
+		buffer.WriteString(fmt.Sprintf("\t%s[%q] = %s\n", paramVarName, c, varNames[i]))
+	}
+	if message {
+		buffer.WriteString(fmt.Sprintf("\treturn %s.FormatNamed(%s, %s)\n}\n", managerName, constName, paramVarName))
+	} else {
+		buffer.WriteString(fmt.Sprintf("\treturn %s.BuildWithStmt(%s, %s)\n}\n", managerName, constName, paramVarName))
+	}
+	return useTime
+}
+
+const (
+	anyType    = "interface{}"
+	timeType   = "time.Time"
+	stringType = "string"
+)
+
+// argumentType derives go type of root parameter from its declarations.
+// declarations without type hint are ignored and conflicting hints fall back to interface{}
+func argumentType(root string, columns []stringman.ColumnBind) string {
+	argType := ""
+	for _, c := range columns {
+		if c.RootName() != root {
+			continue
+		}
+		// nested path like {User.Name} says nothing about root type
+		if c.Name() != root {
+			return anyType
+		}
+		hint := columnType(c)
+		if len(hint) == 0 {
+			continue
+		}
+		if len(argType) > 0 && argType != hint {
+			return anyType
+		}
+		argType = hint
+	}
+
+	if len(argType) == 0 {
+		return anyType
+	}
+	return argType
+}
+
+// columnType returns type hint of declaration. empty if any type is accepted
+func columnType(c stringman.ColumnBind) string {
+	if c.IsArray() {
+		return ""
+	}
+
+	switch c.ChoiceKind() {
+	case "plural":
+		return "int"
+	case "select":
+		return stringType
+	}
+
+	// first filter receives the value
+	if filters := c.FilterNames(); len(filters) > 0 {
+		switch filters[0] {
+		case "date":
+			return timeType
+		case "upper", "lower", "trim", "truncate":
+			return stringType
+		}
+		return ""
+	}
+
+	if value, ok := c.DefaultValue(); ok {
+		return literalType(value)
+	}
+	return ""
+}
+
+// literalType returns type of default literal. e.g) 100 => int, 'abc' => string
+func literalType(value string) string {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return "int"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return "float64"
+	}
+	if value == "true" || value == "false" {
+		return "bool"
+	}
+	if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		return stringType
+	}
+	return ""
+}
+
+// exportedName converts statement id to exported go identifier. e.g) selectCity => SelectCity
+func exportedName(id string) string {
+	name := identifier(id)
+	if len(name) == 0 {
+		return "X"
+	}
+	runes := []rune(name)
+	if runes[0] == '_' {
+		return "X" + name
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// argumentName converts column name to unexported go identifier not used yet. e.g) MemberType => memberType
+func argumentName(column string, used map[string]bool) string {
+	name := identifier(column)
+	runes := []rune(name)
+	if len(runes) == 0 {
+		runes = []rune("arg")
+	}
+	runes[0] = unicode.ToLower(runes[0])
+	name = string(runes)
+
+	for token.IsKeyword(name) || isReservedName(name) || used[name] {
+		name = name + "_"
+	}
+	used[name] = true
+	return name
+}
+
+func isReservedName(name string) bool {
+	switch name {
+	case managerName, paramVarName, optionalName, argsName, dataName, "stringman":
+		return true
+	}
+	return false
+}
+
+// identifier removes characters not allowed in go identifier
+func identifier(s string) string {
+	var buffer bytes.Buffer
+	for i, c := range s {
+		if unicode.IsLetter(c) || c == '_' || (i > 0 && unicode.IsDigit(c)) {
+			buffer.WriteRune(c)
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,110 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatima-go/stringman"
+	"github.com/stretchr/testify/assert"
+)
+
+var genXml = []byte(`
+<?xml version="1.0" encoding="UTF-8" ?>
+<query>
+	<text id="UpdateAlbum">
+		UPDATE album SET score={Score} WHERE id={Id} AND type={type}
+	</text>
+	<text id="SelectCity">
+		SELECT * FROM CITY WHERE NAME={Name} LIMIT {Limit=100}
+	</text>
+	<text id="SelectCityTemplate" engine="template">
+		SELECT * FROM city WHERE age > {{sql .Age}}
+	</text>
+	<text id="InsertOrder">
+		INSERT INTO orders VALUES ({Order.Id}, {Order.Items[0].Sku}, {Memo?})
+	</text>
+	<text id="SelectEvent">
+		SELECT * FROM event WHERE day={Day|date:"2006-01-02"} AND name={Name|upper} AND code={Code}
+		AND score > {Score} AND score != {Score=100} AND ref={Ref|upper} AND ref != {Ref|date}
+	</text>
+	<text id="TicketCount">
+		{Count, plural, one{# ticket} other{# tickets}} for {Gender, select, male{him} other{them}}
+	</text>
+	<text id="NotifyTicketReserved">
+		{UserName}님, 예매가 완료되었습니다. {Memo?}
+	</text>
+	<text id="completeFormatText">
+		hello %s. your level is %d
+	</text>
+</query>
+`)
+
+func TestGenerate(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-gen")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.WriteFile(filepath.Join(dir, "string.gen.xml"), genXml, 0644)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	man, err := stringman.NewStringman(stringman.NewStringmanPreference(dir))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	src, err := generate(man.Statements(), "texts", []string{"Notify*"})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	code := string(src)
+	assert.True(t, strings.HasPrefix(code, "// Code generated by stringman-gen. DO NOT EDIT."))
+	assert.Contains(t, code, `IdUpdateAlbum          = "UpdateAlbum"`)
+	assert.Contains(t, code, "func UpdateAlbum(man *stringman.StringMan, score interface{}, id interface{}, type_ interface{}) (string, error)")
+	assert.Contains(t, code, "func SelectCity(man *stringman.StringMan, name interface{}, optional stringman.BuildParam) (string, error)")
+	assert.Contains(t, code, "func InsertOrder(man *stringman.StringMan, order interface{}, optional stringman.BuildParam) (string, error)")
+	assert.Contains(t, code, `p["Order"] = order`)
+	assert.Contains(t, code, "func SelectEvent(man *stringman.StringMan, day time.Time, name string, code interface{}, score int, ref interface{}) (string, error)")
+	assert.Contains(t, code, "func TicketCount(man *stringman.StringMan, count int, gender string) (string, error)")
+	assert.Contains(t, code, "return man.FormatNamed(IdTicketCount, p)")
+	assert.Contains(t, code, "func NotifyTicketReserved(man *stringman.StringMan, userName interface{}, optional stringman.BuildParam) (string, error)")
+	assert.Contains(t, code, "return man.FormatNamed(IdNotifyTicketReserved, p)")
+	assert.Contains(t, code, "return man.BuildWithStmt(IdUpdateAlbum, p)")
+	assert.Contains(t, code, "\t\"time\"\n")
+	assert.Contains(t, code, "func CompleteFormatText(man *stringman.StringMan, args ...interface{}) (string, error)")
+	assert.Contains(t, code, "return man.FormatWithStmt(IdCompleteFormatText, args...)")
+	assert.Contains(t, code, "func SelectCityTemplate(man *stringman.StringMan, data interface{}) (string, error)")
+}
+
+func TestGenerateInvalidPattern(t *testing.T) {
+	_, err := generate([]stringman.QueryStatement{{Id: "Notify"}}, "texts", []string{"[Notify"})
+	assert.NotNil(t, err)
+}
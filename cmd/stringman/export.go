@@ -0,0 +1,71 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatima-go/stringman"
+)
+
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	path, fileset := sourceFlags(fs)
+	format := fs.String("format", string(stringman.ExportJson), "output format (json|xml)")
+	out := fs.String("out", "", "output file. stdout if empty")
+	locales := localesFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+
+	pref := stringman.NewStringmanPreference(*path)
+	pref.Fileset = *fileset
+	pref.FileLocales = splitLocales(*locales)
+	man, err := stringman.NewStringman(pref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fail to load : %s\n", err.Error())
+		return exitFailure
+	}
+
+	if len(*out) == 0 {
+		err = man.Export(os.Stdout, stringman.ExportFormat(*format))
+	} else {
+		err = exportFile(man, stringman.ExportFormat(*format), *out)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fail to export : %s\n", err.Error())
+		return exitFailure
+	}
+	return exitOk
+}
+
+// exportFile writes to temp file in same directory and renames it on success
+// so failed export does not leave partial file
+func exportFile(man *stringman.StringMan, format stringman.ExportFormat, out string) error {
+	return writeFileAtomic(out, 0644, func(w io.Writer) error {
+		return man.Export(w, format)
+	})
+}
@@ -0,0 +1,100 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatima-go/stringman"
+)
+
+func runFmt(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	path, fileset := sourceFlags(fs)
+	check := fs.Bool("check", false, "list unformatted files without rewriting and fail if any")
+	sortById := fs.Bool("sort", false, "sort statements by id")
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+
+	files, err := filepath.Glob(filepath.Join(*path, *fileset))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fail to search files : %s\n", err.Error())
+		return exitFailure
+	}
+
+	unformatted := 0
+	for _, file := range files {
+		if !strings.EqualFold(filepath.Ext(file), ".xml") {
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fail to read %s : %s\n", file, err.Error())
+			return exitFailure
+		}
+
+		formatted, err := stringman.CanonicalXml(data, *sortById)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fail to format %s : %s\n", file, err.Error())
+			return exitFailure
+		}
+		if bytes.Equal(data, formatted) {
+			continue
+		}
+
+		unformatted++
+		fmt.Fprintln(os.Stdout, file)
+		if *check {
+			continue
+		}
+		if err := rewriteFile(file, formatted); err != nil {
+			fmt.Fprintf(os.Stderr, "fail to write %s : %s\n", file, err.Error())
+			return exitFailure
+		}
+	}
+
+	if *check && unformatted > 0 {
+		return exitIssue
+	}
+	return exitOk
+}
+
+// rewriteFile replaces file through temp file keeping its permission
+func rewriteFile(file string, data []byte) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(file, info.Mode().Perm(), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
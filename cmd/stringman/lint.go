@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatima-go/stringman"
+)
+
+func runLint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	path, fileset := sourceFlags(fs)
+	asJson := fs.Bool("json", false, "print issues as json")
+	locales := localesFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+
+	pref := stringman.NewStringmanPreference(*path)
+	pref.Fileset = *fileset
+	pref.FileLocales = splitLocales(*locales)
+	issues, err := stringman.Lint(pref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fail to lint : %s\n", err.Error())
+		return exitFailure
+	}
+
+	if err := printIssues(os.Stdout, issues, *asJson); err != nil {
+		fmt.Fprintf(os.Stderr, "fail to print : %s\n", err.Error())
+		return exitFailure
+	}
+
+	if len(issues) > 0 {
+		return exitIssue
+	}
+	return exitOk
+}
+
+func printIssues(w io.Writer, issues []stringman.LintIssue, asJson bool) error {
+	if asJson {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(issues)
+	}
+
+	for _, v := range issues {
+		if _, err := fmt.Fprintln(w, v.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
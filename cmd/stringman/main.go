@@ -0,0 +1,128 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+// stringman is a command line tool for statement files
+//
+//	stringman lint -path ./resources -fileset "string*.xml" [-locales en,ko] [-json]
+//	stringman fmt -path ./resources -fileset "string*.xml" [-check] [-sort]
+//	stringman export -path ./resources -fileset "string*.xml" [-locales en,ko] [-format json|xml] [-out file]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	exitOk      = 0
+	exitIssue   = 1
+	exitFailure = 2
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) int
+}
+
+var commands = []command{
+	{"lint", "check statement files", runLint},
+	{"fmt", "rewrite xml statement files canonically", runFmt},
+	{"export", "print loaded statements as json or xml", runExport},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitFailure
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %s\n", args[0])
+	usage()
+	return exitFailure
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: stringman <command> [arguments]\n\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-8s %s\n", c.name, c.usage)
+	}
+}
+
+// sourceFlags registers flags for locating statement files
+func sourceFlags(fs *flag.FlagSet) (*string, *string) {
+	path := fs.String("path", ".", "directory of statement files")
+	fileset := fs.String("fileset", "string*.xml", "glob pattern of statement files")
+	return path, fileset
+}
+
+// localesFlag registers flag for locales of per-locale files. e.g) -locales en,ko
+func localesFlag(fs *flag.FlagSet) *string {
+	return fs.String("locales", "", "comma separated locales of per-locale files like string.en.xml")
+}
+
+func splitLocales(locales string) []string {
+	list := make([]string, 0)
+	for _, l := range strings.Split(locales, ",") {
+		if l = strings.TrimSpace(l); len(l) > 0 {
+			list = append(list, l)
+		}
+	}
+	return list
+}
+
+// writeFileAtomic writes to temp file in same directory and renames it to file on success.
+// file keeps its previous content when write fails
+func writeFileAtomic(file string, mode os.FileMode, write func(w io.Writer) error) error {
+	temp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	err = write(temp)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Chmod(temp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), file)
+}
@@ -0,0 +1,108 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatima-go/stringman"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunLint(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-cmd")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "string.a.xml")
+	assert.Nil(t, os.WriteFile(file, []byte(`<query><text id="A">SELECT {Id}</text></query>`), 0600))
+	assert.Equal(t, exitOk, run([]string{"lint", "-path", dir}))
+
+	assert.Nil(t, os.WriteFile(file, []byte(`<query><text id="A">SELECT {Id</text></query>`), 0644))
+	assert.Equal(t, exitIssue, run([]string{"lint", "-path", dir}))
+
+	assert.Equal(t, exitFailure, run([]string{"unknown"}))
+}
+
+func TestPrintIssuesJson(t *testing.T) {
+	var buffer bytes.Buffer
+	issues := []stringman.LintIssue{{File: "a.xml", Id: "A", Rule: stringman.LintRuleDuplicatedId, Message: "dup"}}
+	assert.Nil(t, printIssues(&buffer, issues, true))
+	assert.Contains(t, buffer.String(), `"rule": "duplicated-id"`)
+}
+
+func TestRunExport(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-cmd")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.a.xml"), []byte(`<query><text id="A">SELECT {Id}</text></query>`), 0644))
+	out := filepath.Join(dir, "export.xml")
+	assert.Equal(t, exitOk, run([]string{"export", "-path", dir, "-format", "xml", "-out", out}))
+
+	data, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `<statement id="A"`)
+
+	assert.Equal(t, exitFailure, run([]string{"export", "-path", dir, "-format", "csv", "-out", out}))
+	kept, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), string(kept))
+
+	files, err := filepath.Glob(out + ".*")
+	assert.Nil(t, err)
+	assert.Empty(t, files)
+}
+
+func TestRunFmt(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-cmd")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "string.a.xml")
+	assert.Nil(t, os.WriteFile(file, []byte(`<query><text id="A">SELECT {Id}</text></query>`), 0600))
+	assert.Equal(t, exitIssue, run([]string{"fmt", "-path", dir, "-check"}))
+	assert.Equal(t, exitOk, run([]string{"fmt", "-path", dir}))
+	assert.Equal(t, exitOk, run([]string{"fmt", "-path", dir, "-check"}))
+
+	data, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, "<query>\n    <text id=\"A\">\n        SELECT {Id}\n    </text>\n</query>\n", string(data))
+
+	info, err := os.Stat(file)
+	if assert.Nil(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+}
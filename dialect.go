@@ -0,0 +1,147 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DialectMySQL Dialect = iota
+	DialectPostgres
+)
+
+type Dialect uint8
+
+func (d Dialect) String() string {
+	switch d {
+	case DialectMySQL:
+		return "MYSQL"
+	case DialectPostgres:
+		return "POSTGRES"
+	}
+	return "UNKNOWN"
+}
+
+// LiteralFormat returns default literal format of dialect
+func (d Dialect) LiteralFormat() LiteralFormat {
+	format := LiteralFormat{}
+	format.TimeLayout = sqlyyyyMMddHHmmss
+	format.FloatMode = FloatFormatFixed
+	format.FloatPrecision = 6
+	format.EscapeBackslash = true
+
+	switch d {
+	case DialectPostgres:
+		format.TimeFraction = 6
+		format.FloatMode = FloatFormatShortest
+		format.EscapeBackslash = false
+	}
+	return format
+}
+
+const (
+	// FloatFormatFixed formats with FloatPrecision digits. e.g) 16.720000
+	FloatFormatFixed FloatFormatMode = iota
+	// FloatFormatShortest formats with the shortest text to round-trip. e.g) 16.72, 1e+21
+	FloatFormatShortest
+	// FloatFormatDecimal formats like shortest but never uses exponent. e.g) 1000000000000000000000
+	FloatFormatDecimal
+)
+
+type FloatFormatMode uint8
+
+func (f FloatFormatMode) String() string {
+	switch f {
+	case FloatFormatFixed:
+		return "FIXED"
+	case FloatFormatShortest:
+		return "SHORTEST"
+	case FloatFormatDecimal:
+		return "DECIMAL"
+	}
+	return "UNKNOWN"
+}
+
+// LiteralFormat controls how time, float and string values are written in completed text
+type LiteralFormat struct {
+	TimeLayout      string
+	TimeLocation    *time.Location // convert time to this location before formatting. nil keeps value's location
+	TimeFraction    int            // digits of fractional seconds (0~9). applied only to built-in layout
+	FloatMode       FloatFormatMode
+	FloatPrecision  int  // used with FloatFormatFixed
+	EscapeBackslash bool // escape backslash in string literal. mysql treats backslash as escape character
+}
+
+func (f LiteralFormat) String() string {
+	return fmt.Sprintf("timeLayout=%s,timeLocation=%v,timeFraction=%d,floatMode=%s,floatPrecision=%d,escapeBackslash=%t",
+		f.TimeLayout, f.TimeLocation, f.TimeFraction, f.FloatMode, f.FloatPrecision, f.EscapeBackslash)
+}
+
+// quoteString writes string literal. single quote is doubled so value can not close the literal
+func (f LiteralFormat) quoteString(s string) string {
+	if f.EscapeBackslash {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (f LiteralFormat) formatTime(t time.Time) string {
+	if f.TimeLocation != nil {
+		t = t.In(f.TimeLocation)
+	}
+
+	layout := f.TimeLayout
+	if len(layout) == 0 {
+		layout = sqlyyyyMMddHHmmss
+	}
+	// custom layout controls its own fractional seconds
+	if layout == sqlyyyyMMddHHmmss && f.TimeFraction > 0 {
+		fraction := f.TimeFraction
+		if fraction > 9 {
+			fraction = 9
+		}
+		layout = layout + "." + strings.Repeat("0", fraction)
+	}
+
+	return t.Format(layout)
+}
+
+// formatFloat fails with NaN and Inf since they have no sql literal
+func (f LiteralFormat) formatFloat(v float64, bitSize int) (string, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "", fmt.Errorf("not finite float %v", v)
+	}
+
+	switch f.FloatMode {
+	case FloatFormatShortest:
+		return strconv.FormatFloat(v, 'g', -1, bitSize), nil
+	case FloatFormatDecimal:
+		return strconv.FormatFloat(v, 'f', -1, bitSize), nil
+	}
+	return strconv.FormatFloat(v, 'f', f.FloatPrecision, bitSize), nil
+}
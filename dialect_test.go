@@ -0,0 +1,98 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLiteralFormatFloat(t *testing.T) {
+	format := DialectMySQL.LiteralFormat()
+	assertFloat(t, "16.720000", format, 16.72, 64)
+
+	format.FloatPrecision = 2
+	assertFloat(t, "16.72", format, 16.72, 64)
+
+	format.FloatMode = FloatFormatShortest
+	assertFloat(t, "16.72", format, 16.72, 64)
+	assertFloat(t, "16.72", format, float64(float32(16.72)), 32)
+	assertFloat(t, "1e+21", format, 1e21, 64)
+
+	format.FloatMode = FloatFormatDecimal
+	assertFloat(t, "1000000000000000000000", format, 1e21, 64)
+
+	_, err := format.formatFloat(math.NaN(), 64)
+	assert.NotNil(t, err)
+	_, err = format.formatFloat(math.Inf(1), 64)
+	assert.NotNil(t, err)
+	_, err = format.formatFloat(math.Inf(-1), 64)
+	assert.NotNil(t, err)
+}
+
+func assertFloat(t *testing.T, expected string, format LiteralFormat, v float64, bitSize int) {
+	text, err := format.formatFloat(v, bitSize)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, text)
+}
+
+func TestLiteralFormatTime(t *testing.T) {
+	kst := time.FixedZone("KST", 9*60*60)
+	v := time.Date(2024, 12, 1, 12, 0, 0, 123456789, kst)
+
+	format := DialectMySQL.LiteralFormat()
+	assert.Equal(t, "2024-12-01 12:00:00", format.formatTime(v))
+
+	format.TimeLocation = time.UTC
+	assert.Equal(t, "2024-12-01 03:00:00", format.formatTime(v))
+
+	format.TimeFraction = 3
+	assert.Equal(t, "2024-12-01 03:00:00.123", format.formatTime(v))
+
+	format = DialectPostgres.LiteralFormat()
+	assert.Equal(t, "2024-12-01 12:00:00.123456", format.formatTime(v))
+
+	format.TimeLayout = "2006-01-02T15:04:05.000"
+	assert.Equal(t, "2024-12-01T12:00:00.123", format.formatTime(v))
+}
+
+func TestLiteralFormatQuote(t *testing.T) {
+	format := DialectMySQL.LiteralFormat()
+	assert.Equal(t, `'it''s'`, format.quoteString(`it's`))
+	assert.Equal(t, `'a\\'' OR 1=1'`, format.quoteString(`a\' OR 1=1`))
+
+	format = DialectPostgres.LiteralFormat()
+	assert.Equal(t, `'a\'' OR 1=1'`, format.quoteString(`a\' OR 1=1`))
+}
+
+func TestPreferenceDialect(t *testing.T) {
+	pref := NewStringmanPreference("")
+	pref.SetDialect(DialectPostgres)
+	man := newStringMan(pref)
+
+	str, err := man.encoders.asString(16.72)
+	assert.Nil(t, err)
+	assert.Equal(t, "16.72", str)
+}
@@ -24,13 +24,18 @@ package stringman
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"text/template"
 )
 
 const (
 	eleTypeUnknown = iota
 	eleTypeText
 	eleTypeIf
+	eleTypeAllow
+	eleTypeFragment
+	eleTypeInclude
 )
 
 type declareElementType uint8
@@ -41,6 +46,12 @@ func (d declareElementType) String() string {
 		return "TEXT"
 	case eleTypeIf:
 		return "IF"
+	case eleTypeAllow:
+		return "ALLOW"
+	case eleTypeFragment:
+		return "FRAGMENT"
+	case eleTypeInclude:
+		return "INCLUDE"
 	}
 	return "UNKNOWN"
 }
@@ -58,6 +69,12 @@ func buildElementType(stmt string) declareElementType {
 		return eleTypeText
 	case "if":
 		return eleTypeIf
+	case "allow":
+		return eleTypeAllow
+	case "fragment":
+		return eleTypeFragment
+	case "include":
+		return eleTypeInclude
 	}
 	return eleTypeUnknown
 }
@@ -67,25 +84,103 @@ type QueryStatement struct {
 	Query         string `xml:",cdata"`
 	columnMention []ColumnBind
 	HoldedQuery   string
+	allowList     map[string][]string
+	format        formatSpec
+	engine        string
+	tmpl          *template.Template
+	lang          string
+	source        string // file which statement is declared in
+	raw           string // text before normalizing
+	layer         string // name of StatementLayer
+	override      bool   // replaces statement of earlier layer
 }
 
 func (q QueryStatement) String() string {
 	return fmt.Sprintf("id=[%s], queryLen=%d, columnLen=%d", q.Id, len(q.Query), len(q.columnMention))
 }
 
+// IsTemplate returns true when statement is declared with engine="template"
+func (q QueryStatement) IsTemplate() bool {
+	return q.engine == engineTemplate
+}
+
+// Lang returns normalized locale of statement. empty for default statement
+func (q QueryStatement) Lang() string {
+	return q.lang
+}
+
+// Layer returns name of StatementLayer which statement is loaded from
+func (q QueryStatement) Layer() string {
+	return q.layer
+}
+
+// Columns returns variables declared in statement. same name could appear more than once
+func (q QueryStatement) Columns() []ColumnBind {
+	return flattenColumns(q.columnMention)
+}
+
+// flattenColumns includes variables declared inside plural/select messages
+func flattenColumns(columns []ColumnBind) []ColumnBind {
+	flatten := make([]ColumnBind, 0, len(columns))
+	for _, c := range columns {
+		flatten = append(flatten, c)
+		if !c.IsChoice() {
+			continue
+		}
+		for _, m := range c.choice.cases {
+			flatten = append(flatten, flattenColumns(m.stmt.columnMention)...)
+		}
+	}
+	return flatten
+}
+
+// isAllowed checks raw substitution value against allow-list declared in xml.
+// without allow-list, value should be a plain identifier
+func (q QueryStatement) isAllowed(name string, value string) bool {
+	allowed, ok := q.allowList[name]
+	if !ok {
+		return identifierRegex.MatchString(value)
+	}
+
+	for _, v := range allowed {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (q QueryStatement) hasRequiredColumn() bool {
+	for _, c := range q.Columns() {
+		if c.IsRequired() {
+			return true
+		}
+	}
+	return false
+}
+
 type ColumnBind struct {
-	name     string
-	holdPos  int
-	bindType columnBindType
+	name         string
+	holdPos      int
+	bindType     columnBindType
+	defaultValue string
+	hasDefault   bool
+	optional     bool
+	filters      []filterCall
+	path         []paramSegment // nested path like User.Name. nil for plain name
+	choice       *messageChoice
 }
 
 func (c ColumnBind) String() string {
-	return fmt.Sprintf("name=%s,holdPos=%d,bindType=%s", c.name, c.holdPos, c.bindType)
+	return fmt.Sprintf("name=%s,holdPos=%d,bindType=%s,default=%s,optional=%v,filters=%v",
+		c.name, c.holdPos, c.bindType, c.defaultValue, c.optional, c.filters)
 }
 
 const (
 	columnBindTypeNormal = iota
 	columnBindTypeArray
+	columnBindTypeRaw
+	columnBindTypeChoice
 )
 
 type columnBindType uint8
@@ -96,6 +191,10 @@ func (c columnBindType) String() string {
 		return "NORMAL"
 	case columnBindTypeArray:
 		return "ARRAY"
+	case columnBindTypeRaw:
+		return "RAW"
+	case columnBindTypeChoice:
+		return "CHOICE"
 	}
 	return "UNKNOWN"
 }
@@ -116,6 +215,99 @@ func NewColumnBindArray(name string, pos int) ColumnBind {
 	return b
 }
 
+func (c ColumnBind) IsChoice() bool {
+	return c.bindType == columnBindTypeChoice
+}
+
+func (c ColumnBind) IsRaw() bool {
+	return c.bindType == columnBindTypeRaw
+}
+
+func (c ColumnBind) IsArray() bool {
+	return c.bindType == columnBindTypeArray
+}
+
 func (c ColumnBind) Name() string {
 	return c.name
 }
+
+// DefaultValue returns declared default text. e.g) 100 for {Limit=100}
+func (c ColumnBind) DefaultValue() (string, bool) {
+	return c.defaultValue, c.hasDefault
+}
+
+// FilterNames returns declared filters in order. e.g) [trim, upper] for {Name|trim|upper}
+func (c ColumnBind) FilterNames() []string {
+	names := make([]string, len(c.filters))
+	for i, f := range c.filters {
+		names[i] = f.name
+	}
+	return names
+}
+
+// ChoiceKind returns plural or select for choice argument. empty for others
+func (c ColumnBind) ChoiceKind() string {
+	if c.choice == nil {
+		return ""
+	}
+	return c.choice.kind
+}
+
+// RootName returns first segment of nested path. e.g) User for User.Name
+func (c ColumnBind) RootName() string {
+	if len(c.path) > 0 {
+		return c.path[0].name
+	}
+	return c.name
+}
+
+// IsRequired returns false when the column declares a default value ({Limit=100})
+// or is marked as optional ({Name?})
+func (c ColumnBind) IsRequired() bool {
+	return !c.hasDefault && !c.optional
+}
+
+const (
+	bindDefaultMark  = "="
+	bindOptionalMark = "?"
+	nullLiteral      = "null"
+)
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)?$`)
+
+// parseColumnBind builds ColumnBind from variable declaration inside delimiter.
+// e.g) Name, Limit=100, Name?, Price|comma, Date|date:"2006.01.02"
+func parseColumnBind(declare string, pos int) (ColumnBind, error) {
+	tokens := splitFilters(declare)
+	b := NewColumnBind(strings.TrimSpace(tokens[0]), pos)
+	for _, t := range tokens[1:] {
+		call, err := parseFilterCall(t)
+		if err != nil {
+			return b, err
+		}
+		if _, ok := findFilter(call.name); !ok {
+			return b, fmt.Errorf("unknown filter %s : %s", call.name, declare)
+		}
+		b.filters = append(b.filters, call)
+	}
+	if idx := strings.Index(b.name, bindDefaultMark); idx >= 0 {
+		b.defaultValue = strings.TrimSpace(b.name[idx+1:])
+		b.hasDefault = true
+		b.name = strings.TrimSpace(b.name[:idx])
+	} else if strings.HasSuffix(b.name, bindOptionalMark) {
+		b.optional = true
+		b.name = strings.TrimSpace(strings.TrimSuffix(b.name, bindOptionalMark))
+	}
+
+	if len(b.name) == 0 {
+		return b, fmt.Errorf("empty variable name : %s", declare)
+	}
+
+	path, err := parseParamPath(b.name)
+	if err != nil {
+		return b, err
+	}
+	b.path = path
+
+	return b, nil
+}
@@ -0,0 +1,224 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Encoder converts value to literal text used in completed text.
+// e.g) string value "hello" => 'hello'
+type Encoder func(v interface{}) (string, error)
+
+type encoderRegistry struct {
+	mutex    sync.RWMutex
+	encoders map[reflect.Type]Encoder
+	format   LiteralFormat
+}
+
+func newEncoderRegistry(format LiteralFormat) *encoderRegistry {
+	r := &encoderRegistry{}
+	r.encoders = make(map[reflect.Type]Encoder)
+	r.format = format
+	return r
+}
+
+func (r *encoderRegistry) register(t reflect.Type, encoder Encoder) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.encoders[t] = encoder
+}
+
+func (r *encoderRegistry) find(t reflect.Type) (Encoder, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	encoder, ok := r.encoders[t]
+	return encoder, ok
+}
+
+// asFallbackString handles types not covered by built-in conversion.
+// driver.Valuer, pointer, named kind (type Status string), [16]byte (uuid) and fmt.Stringer
+func (r *encoderRegistry) asFallbackString(v interface{}) (string, error) {
+	// nil pointer first. Value() or String() of value receiver panics on nil pointer
+	if isNilPointer(v) {
+		return nullLiteral, nil
+	}
+
+	if value, valid, ok := nullWrapperValue(v); ok {
+		if !valid {
+			return nullLiteral, nil
+		}
+		return r.asString(value)
+	}
+
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return "", fmt.Errorf("fail to get value from %v : %s", reflect.TypeOf(v), err.Error())
+		}
+		if value == nil {
+			return nullLiteral, nil
+		}
+		return r.asString(value)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		str, err := r.asString(rv.Elem().Interface())
+		if err == nil {
+			return str, nil
+		}
+	case reflect.String:
+		return r.asString(rv.String())
+	case reflect.Bool:
+		return r.asString(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return r.asString(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return r.asString(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return r.asString(rv.Float())
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return r.asString(rv.Bytes())
+		}
+	case reflect.Array:
+		if rv.Len() == 16 && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("'%s'", formatUUID(rv)), nil
+		}
+	}
+
+	if stringer, ok := v.(fmt.Stringer); ok {
+		return r.asString(stringer.String())
+	}
+
+	return "", fmt.Errorf("unsupported type %v", reflect.TypeOf(v))
+}
+
+// asText converts value to plain text for message. no quoting and null is empty text.
+// float is written with the shortest digits regardless of sql FloatMode. e.g) 16.72
+// registered encoder takes precedence as asString
+func (r *encoderRegistry) asText(v interface{}) (string, error) {
+	if encoder, ok := r.find(reflect.TypeOf(v)); ok {
+		return encoder(v)
+	}
+
+	switch s := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return s, nil
+	case []byte:
+		return string(s), nil
+	case time.Time:
+		return r.format.formatTime(s), nil
+	case float32:
+		return strconv.FormatFloat(float64(s), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64), nil
+	}
+
+	if isNilPointer(v) {
+		return "", nil
+	}
+
+	if value, valid, ok := nullWrapperValue(v); ok {
+		if !valid {
+			return "", nil
+		}
+		return r.asText(value)
+	}
+
+	if stringer, ok := v.(fmt.Stringer); ok {
+		return stringer.String(), nil
+	}
+
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return "", fmt.Errorf("fail to get value from %v : %s", reflect.TypeOf(v), err.Error())
+		}
+		return r.asText(value)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		return r.asText(rv.Elem().Interface())
+	}
+
+	return fmt.Sprint(v), nil
+}
+
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+const sqlPackagePath = "database/sql"
+
+// nullWrapperValue extracts value from database/sql null wrapper which is not covered by type switch.
+// e.g) sql.NullInt16, sql.NullByte, sql.Null[T]
+func nullWrapperValue(v interface{}) (interface{}, bool, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Struct || rv.Type().PkgPath() != sqlPackagePath || rv.NumField() != 2 {
+		return nil, false, false
+	}
+	if !strings.HasPrefix(rv.Type().Name(), "Null") {
+		return nil, false, false
+	}
+
+	valid := rv.FieldByName("Valid")
+	if !valid.IsValid() || valid.Kind() != reflect.Bool {
+		return nil, false, false
+	}
+
+	for i := 0; i < rv.NumField(); i++ {
+		if rv.Type().Field(i).Name != "Valid" {
+			return rv.Field(i).Interface(), valid.Bool(), true
+		}
+	}
+	return nil, false, false
+}
+
+// formatUUID formats 16 bytes array as xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func formatUUID(rv reflect.Value) string {
+	b := make([]byte, 16)
+	for i := 0; i < 16; i++ {
+		b[i] = byte(rv.Index(i).Uint())
+	}
+
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
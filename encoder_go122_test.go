@@ -0,0 +1,63 @@
+//go:build go1.22
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// sql.Null[T] is available since go 1.22
+func TestGenericNullWrapper(t *testing.T) {
+	r := newEncoderRegistry(DialectMySQL.LiteralFormat())
+
+	cases := []struct {
+		value  interface{}
+		expect string
+		text   string
+	}{
+		{sql.Null[string]{V: "hello", Valid: true}, "'hello'", "hello"},
+		{sql.Null[string]{}, "null", ""},
+		{sql.Null[int64]{V: 1234, Valid: true}, "1234", "1234"},
+		{sql.Null[int64]{}, "null", ""},
+		{sql.Null[float64]{V: 16.72, Valid: true}, "16.720000", "16.72"},
+		{sql.Null[time.Time]{V: time.Date(2024, 12, 1, 12, 0, 0, 0, time.Local), Valid: true}, "'2024-12-01 12:00:00'", "2024-12-01 12:00:00"},
+		{sql.Null[time.Time]{}, "null", ""},
+	}
+
+	for _, c := range cases {
+		str, err := r.asString(c.value)
+		if assert.Nil(t, err, "%v", c.value) {
+			assert.Equal(t, c.expect, str)
+		}
+
+		text, err := r.asText(c.value)
+		if assert.Nil(t, err, "%v", c.value) {
+			assert.Equal(t, c.text, text)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testStatus string
+
+type testLevel int
+
+type testUUID [16]byte
+
+type testPoint struct {
+	x, y int
+}
+
+func (p *testPoint) String() string {
+	return "point"
+}
+
+func TestEncoderFallback(t *testing.T) {
+	r := newEncoderRegistry(DialectMySQL.LiteralFormat())
+
+	name := "fatima"
+	age := 12
+	var nilName *string
+	uuid := testUUID{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+
+	cases := []struct {
+		value  interface{}
+		expect string
+	}{
+		{&name, "'fatima'"},
+		{&age, "12"},
+		{nilName, "null"},
+		{testStatus("READY"), "'READY'"},
+		{testLevel(3), "3"},
+		{uuid, "'123e4567-e89b-12d3-a456-426614174000'"},
+		{sql.NullInt32{Int32: 7, Valid: true}, "7"},
+		{sql.NullTime{}, "null"},
+		{&testPoint{}, "'point'"},
+	}
+
+	for _, c := range cases {
+		str, err := r.asString(c.value)
+		if !assert.Nil(t, err, "%v", c.value) {
+			continue
+		}
+		assert.Equal(t, c.expect, str)
+	}
+
+	_, err := r.asString(testPoint{})
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "unsupported type"))
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	man := newStringMan(NewStringmanPreference(""))
+	man.RegisterEncoder(reflect.TypeOf(time.Duration(0)), func(v interface{}) (string, error) {
+		return "'" + v.(time.Duration).String() + "'", nil
+	})
+
+	str, err := man.encoders.asString(3 * time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "'3s'", str)
+
+	str, err = man.encoders.asText(3 * time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "'3s'", str)
+
+	other := newStringMan(NewStringmanPreference(""))
+	str, err = other.encoders.asString(3 * time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "3000000000", str)
+}
+
+func TestNullWrapper(t *testing.T) {
+	r := newEncoderRegistry(DialectMySQL.LiteralFormat())
+	var nilValue interface{}
+	var nilString *sql.NullString
+	var nilInt64 *sql.NullInt64
+	validString := sql.NullString{String: "hello", Valid: true}
+
+	cases := []struct {
+		value  interface{}
+		expect string
+	}{
+		{nilValue, "null"},
+		{sql.NullString{String: "hello", Valid: true}, "'hello'"},
+		{sql.NullInt64{Int64: 1234, Valid: true}, "1234"},
+		{sql.NullInt64{}, "null"},
+		{sql.NullInt32{Int32: 12, Valid: true}, "12"},
+		{sql.NullInt16{Int16: 12, Valid: true}, "12"},
+		{sql.NullByte{Byte: 1, Valid: true}, "1"},
+		{sql.NullByte{}, "null"},
+		{sql.NullBool{Bool: true, Valid: true}, "true"},
+		{sql.NullFloat64{Float64: 16.72, Valid: true}, "16.720000"},
+		{sql.NullTime{Time: time.Date(2024, 12, 1, 12, 0, 0, 0, time.Local), Valid: true}, "'2024-12-01 12:00:00'"},
+		{sql.NullTime{}, "null"},
+		{nilString, "null"},
+		{nilInt64, "null"},
+		{&validString, "'hello'"},
+	}
+
+	for _, c := range cases {
+		str, err := r.asString(c.value)
+		if !assert.Nil(t, err, "%v", c.value) {
+			continue
+		}
+		assert.Equal(t, c.expect, str)
+	}
+}
+
+func TestAsText(t *testing.T) {
+	r := newEncoderRegistry(DialectMySQL.LiteralFormat())
+	name := "fatima"
+	var nilName *string
+	var nilString *sql.NullString
+	var nilInt64 *sql.NullInt64
+
+	cases := []struct {
+		value  interface{}
+		expect string
+	}{
+		{"it's", "it's"},
+		{&name, "fatima"},
+		{nilName, ""},
+		{12, "12"},
+		{true, "true"},
+		{testStatus("READY"), "READY"},
+		{sql.NullString{}, ""},
+		{sql.NullInt64{Int64: 3, Valid: true}, "3"},
+		{16.72, "16.72"},
+		{float32(0.1), "0.1"},
+		{1e21, "1000000000000000000000"},
+		{nilString, ""},
+		{nilInt64, ""},
+		{time.Date(2024, 12, 1, 12, 0, 0, 0, time.Local), "2024-12-01 12:00:00"},
+	}
+
+	for _, c := range cases {
+		str, err := r.asText(c.value)
+		if !assert.Nil(t, err, "%v", c.value) {
+			continue
+		}
+		assert.Equal(t, c.expect, str)
+	}
+}
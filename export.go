@@ -0,0 +1,150 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ExportFormat is output format of Export
+type ExportFormat string
+
+const (
+	ExportJson ExportFormat = "json"
+	ExportXml  ExportFormat = "xml"
+)
+
+type exportCatalog struct {
+	XMLName    xml.Name          `json:"-" xml:"catalog"`
+	Statements []exportStatement `json:"statements" xml:"statement"`
+}
+
+type exportStatement struct {
+	Id       string         `json:"id" xml:"id,attr"`
+	Lang     string         `json:"lang,omitempty" xml:"lang,attr,omitempty"`
+	Source   string         `json:"source,omitempty" xml:"source,attr,omitempty"`
+	Layer    string         `json:"layer,omitempty" xml:"layer,attr,omitempty"`
+	Text     string         `json:"text" xml:"text"`
+	Query    string         `json:"query" xml:"query"`
+	Params   []exportParam  `json:"params" xml:"params>param"`
+	Metadata exportMetadata `json:"metadata" xml:"metadata"`
+}
+
+type exportParam struct {
+	Name     string `json:"name" xml:"name,attr"`
+	Type     string `json:"type" xml:"type,attr"`
+	Required bool   `json:"required" xml:"required,attr"`
+	Default  string `json:"default,omitempty" xml:"default,attr,omitempty"`
+}
+
+type exportMetadata struct {
+	Engine string        `json:"engine,omitempty" xml:"engine,omitempty"`
+	Allow  []exportAllow `json:"allow,omitempty" xml:"allow,omitempty"`
+}
+
+type exportAllow struct {
+	Param  string   `json:"param" xml:"param,attr"`
+	Values []string `json:"values" xml:"value"`
+}
+
+// Export writes every loaded statement including locale statements ordered by id and lang
+func (man *StringMan) Export(w io.Writer, format ExportFormat) error {
+	statements := man.statements()
+	catalog := exportCatalog{Statements: make([]exportStatement, 0, len(statements.statementMap))}
+	for _, v := range statements.statementMap {
+		catalog.Statements = append(catalog.Statements, newExportStatement(v))
+	}
+	for _, locale := range statements.localeMap {
+		for _, v := range locale {
+			catalog.Statements = append(catalog.Statements, newExportStatement(v))
+		}
+	}
+	sort.Slice(catalog.Statements, func(i, j int) bool {
+		if catalog.Statements[i].Id != catalog.Statements[j].Id {
+			return catalog.Statements[i].Id < catalog.Statements[j].Id
+		}
+		return catalog.Statements[i].Lang < catalog.Statements[j].Lang
+	})
+
+	switch format {
+	case ExportJson:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(catalog)
+	case ExportXml:
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		if err := enc.Encode(catalog); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+
+	return fmt.Errorf("unsupported export format : %s", format)
+}
+
+func newExportStatement(stmt QueryStatement) exportStatement {
+	export := exportStatement{
+		Id:     stmt.Id,
+		Lang:   stmt.lang,
+		Source: stmt.source,
+		Layer:  stmt.layer,
+		Text:   stmt.raw,
+		Query:  stmt.Query,
+		Params: make([]exportParam, 0),
+	}
+
+	declared := make(map[string]bool)
+	for _, c := range stmt.Columns() {
+		if declared[c.name] {
+			continue
+		}
+		declared[c.name] = true
+		export.Params = append(export.Params, exportParam{
+			Name:     c.name,
+			Type:     c.bindType.String(),
+			Required: c.IsRequired(),
+			Default:  c.defaultValue,
+		})
+	}
+
+	export.Metadata.Engine = stmt.engine
+	params := make([]string, 0, len(stmt.allowList))
+	for k := range stmt.allowList {
+		params = append(params, k)
+	}
+	sort.Strings(params)
+	for _, k := range params {
+		export.Metadata.Allow = append(export.Metadata.Allow, exportAllow{Param: k, Values: stmt.allowList[k]})
+	}
+
+	return export
+}
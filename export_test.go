@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var exportXml = []byte(`
+<?xml version="1.0" encoding="UTF-8" ?>
+<query>
+	<text id="SelectShardTrack">
+		<allow param="Sort">track_id, create_time</allow>
+		SELECT * FROM ted_track_temp_${Shard} WHERE name={Name} AND age > {Age=20} ORDER BY ${Sort}
+	</text>
+	<text id="Greeting" lang="en">
+		Hello %s
+	</text>
+	<text id="Greeting">
+		안녕하세요 %s
+	</text>
+</query>
+`)
+
+func TestExport(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-export")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "string.xml")
+	assert.Nil(t, os.WriteFile(file, exportXml, 0644))
+
+	man, err := NewStringman(NewStringmanPreference(dir))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	var buffer bytes.Buffer
+	if !assert.Nil(t, man.Export(&buffer, ExportJson)) {
+		return
+	}
+
+	catalog := exportCatalog{}
+	if !assert.Nil(t, json.Unmarshal(buffer.Bytes(), &catalog)) {
+		return
+	}
+	if !assert.Equal(t, 3, len(catalog.Statements)) {
+		return
+	}
+	assert.Equal(t, "Greeting", catalog.Statements[0].Id)
+	assert.Equal(t, "", catalog.Statements[0].Lang)
+	assert.Equal(t, "en", catalog.Statements[1].Lang)
+
+	track := catalog.Statements[2]
+	assert.Equal(t, "SelectShardTrack", track.Id)
+	assert.Equal(t, file, track.Source)
+	assert.Equal(t, "SELECT * FROM ted_track_temp_${Shard} WHERE name={Name} AND age > {Age=20} ORDER BY ${Sort}", track.Text)
+	assert.Equal(t, "SELECT * FROM ted_track_temp_? WHERE name=? AND age > ? ORDER BY ?", track.Query)
+	assert.Equal(t, []exportParam{
+		{Name: "Shard", Type: "RAW", Required: true},
+		{Name: "Name", Type: "NORMAL", Required: true},
+		{Name: "Age", Type: "NORMAL", Required: false, Default: "20"},
+		{Name: "Sort", Type: "RAW", Required: true},
+	}, track.Params)
+	assert.Equal(t, []exportAllow{{Param: "Sort", Values: []string{"track_id", "create_time"}}}, track.Metadata.Allow)
+
+	buffer.Reset()
+	if !assert.Nil(t, man.Export(&buffer, ExportXml)) {
+		return
+	}
+	exported := buffer.String()
+	assert.True(t, strings.HasPrefix(exported, "<?xml"))
+	assert.Contains(t, exported, `<statement id="Greeting" lang="en" source="`+file+`" layer="base">`)
+	assert.Contains(t, exported, `<param name="Age" type="NORMAL" required="false" default="20"></param>`)
+	assert.Contains(t, exported, `<allow param="Sort">`)
+
+	assert.NotNil(t, man.Export(&buffer, ExportFormat("csv")))
+}
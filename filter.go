@@ -0,0 +1,228 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Filter converts value before rendering. arg is the text after ':' in declaration
+// e.g) {Date|date:"2006.01.02"} calls date filter with arg 2006.01.02
+type Filter func(value interface{}, arg string) (interface{}, error)
+
+var (
+	filterMutex    sync.RWMutex
+	filterRegistry = map[string]Filter{
+		"upper":    filterUpper,
+		"lower":    filterLower,
+		"trim":     filterTrim,
+		"comma":    filterComma,
+		"date":     filterDate,
+		"truncate": filterTruncate,
+	}
+)
+
+// RegisterFilter adds (or replaces) filter usable in placeholder declaration.
+// filters should be registered before NewStringman since unknown filter fails loading
+func RegisterFilter(name string, filter Filter) {
+	filterMutex.Lock()
+	defer filterMutex.Unlock()
+	filterRegistry[name] = filter
+}
+
+func findFilter(name string) (Filter, bool) {
+	filterMutex.RLock()
+	defer filterMutex.RUnlock()
+	f, ok := filterRegistry[name]
+	return f, ok
+}
+
+const (
+	filterSeparator    = '|'
+	filterArgSeparator = ":"
+)
+
+type filterCall struct {
+	name string
+	arg  string
+}
+
+func (f filterCall) String() string {
+	if len(f.arg) == 0 {
+		return f.name
+	}
+	return f.name + filterArgSeparator + f.arg
+}
+
+// splitFilters splits declaration with '|' except inside double quote.
+// e.g) Date|date:"2006|01" => [Date, date:"2006|01"]
+func splitFilters(declare string) []string {
+	tokens := make([]string, 0)
+	quoted := false
+	start := 0
+	for i := 0; i < len(declare); i++ {
+		switch declare[i] {
+		case '"':
+			quoted = !quoted
+		case filterSeparator:
+			if quoted {
+				continue
+			}
+			tokens = append(tokens, declare[start:i])
+			start = i + 1
+		}
+	}
+	return append(tokens, declare[start:])
+}
+
+func parseFilterCall(declare string) (filterCall, error) {
+	call := filterCall{}
+	declare = strings.TrimSpace(declare)
+	idx := strings.Index(declare, filterArgSeparator)
+	if idx < 0 {
+		call.name = declare
+	} else {
+		call.name = strings.TrimSpace(declare[:idx])
+		call.arg = strings.TrimSpace(declare[idx+1:])
+		if strings.HasPrefix(call.arg, "\"") {
+			arg, err := strconv.Unquote(call.arg)
+			if err != nil {
+				return call, fmt.Errorf("invalid filter argument : %s", declare)
+			}
+			call.arg = arg
+		}
+	}
+
+	if len(call.name) == 0 {
+		return call, fmt.Errorf("empty filter name : %s", declare)
+	}
+	return call, nil
+}
+
+func applyFilters(calls []filterCall, value interface{}) (interface{}, error) {
+	for _, c := range calls {
+		f, ok := findFilter(c.name)
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %s", c.name)
+		}
+		v, err := f(value, c.arg)
+		if err != nil {
+			return nil, fmt.Errorf("fail to apply filter %s : %s", c.name, err.Error())
+		}
+		value = v
+	}
+	return value, nil
+}
+
+func filterUpper(value interface{}, arg string) (interface{}, error) {
+	return strings.ToUpper(fmt.Sprint(value)), nil
+}
+
+func filterLower(value interface{}, arg string) (interface{}, error) {
+	return strings.ToLower(fmt.Sprint(value)), nil
+}
+
+func filterTrim(value interface{}, arg string) (interface{}, error) {
+	return strings.TrimSpace(fmt.Sprint(value)), nil
+}
+
+// filterComma formats number with thousands separator. e.g) 1234567 => 1,234,567
+func filterComma(value interface{}, arg string) (interface{}, error) {
+	var str string
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		str = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		str = strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		str = strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.String:
+		if _, err := strconv.ParseFloat(v.String(), 64); err != nil {
+			return nil, fmt.Errorf("not a number : %s", v.String())
+		}
+		str = v.String()
+	default:
+		return nil, fmt.Errorf("unsupported type %v", reflect.TypeOf(value))
+	}
+
+	sign := ""
+	if strings.HasPrefix(str, "-") {
+		sign = "-"
+		str = str[1:]
+	}
+	fraction := ""
+	if idx := strings.IndexByte(str, '.'); idx >= 0 {
+		fraction = str[idx:]
+		str = str[:idx]
+	}
+
+	var buffer strings.Builder
+	for i, c := range str {
+		if i > 0 && (len(str)-i)%3 == 0 {
+			buffer.WriteByte(',')
+		}
+		buffer.WriteRune(c)
+	}
+
+	return sign + buffer.String() + fraction, nil
+}
+
+// filterDate formats time with layout in arg. default layout is 2006-01-02 15:04:05
+func filterDate(value interface{}, arg string) (interface{}, error) {
+	layout := arg
+	if len(layout) == 0 {
+		layout = sqlyyyyMMddHHmmss
+	}
+
+	switch t := value.(type) {
+	case time.Time:
+		return t.Format(layout), nil
+	case *time.Time:
+		if t == nil {
+			return nil, fmt.Errorf("nil time")
+		}
+		return t.Format(layout), nil
+	}
+	return nil, fmt.Errorf("unsupported type %v", reflect.TypeOf(value))
+}
+
+// filterTruncate cuts text to arg runes
+func filterTruncate(value interface{}, arg string) (interface{}, error) {
+	size, err := strconv.Atoi(arg)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("invalid truncate size : %s", arg)
+	}
+
+	str := fmt.Sprint(value)
+	if utf8.RuneCountInString(str) <= size {
+		return str, nil
+	}
+	return string([]rune(str)[:size]), nil
+}
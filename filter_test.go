@@ -0,0 +1,109 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitFilters(t *testing.T) {
+	tokens := splitFilters(`Date|date:"2006|01"|upper`)
+	assert.Equal(t, []string{"Date", `date:"2006|01"`, "upper"}, tokens)
+
+	call, err := parseFilterCall(`date:"2006.01.02"`)
+	assert.Nil(t, err)
+	assert.Equal(t, "date", call.name)
+	assert.Equal(t, "2006.01.02", call.arg)
+
+	call, err = parseFilterCall("truncate:40")
+	assert.Nil(t, err)
+	assert.Equal(t, "40", call.arg)
+}
+
+func TestBuiltinFilters(t *testing.T) {
+	v, err := filterComma(1234567, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "1,234,567", v)
+
+	v, err = filterComma(-1234.5, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "-1,234.5", v)
+
+	v, err = filterComma(123, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "123", v)
+
+	_, err = filterComma("abc", "")
+	assert.NotNil(t, err)
+
+	v, err = filterDate(time.Date(2024, 12, 1, 12, 0, 0, 0, time.Local), "2006.01.02")
+	assert.Nil(t, err)
+	assert.Equal(t, "2024.12.01", v)
+
+	v, err = filterTruncate("안녕하세요 반갑습니다", "5")
+	assert.Nil(t, err)
+	assert.Equal(t, "안녕하세요", v)
+
+	v, err = filterUpper("fatima", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "FATIMA", v)
+}
+
+func TestCustomFilter(t *testing.T) {
+	RegisterFilter("reverse", func(value interface{}, arg string) (interface{}, error) {
+		runes := []rune(value.(string))
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	})
+	t.Cleanup(func() {
+		unregisterFilter("reverse")
+	})
+
+	stmt := QueryStatement{Query: "SELECT {Name|reverse|upper}"}
+	err := newNormalizer().normalize(&stmt)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	man := newStringMan(NewStringmanPreference(""))
+	built, err := man.completeText(stmt, man.newParamIndex(BuildParam{"Name": "abc"}), renderSql, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT 'CBA'", built)
+
+	stmt = QueryStatement{Query: "SELECT {Name|unknown}"}
+	err = newNormalizer().normalize(&stmt)
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "unknown filter"))
+	}
+}
+
+func unregisterFilter(name string) {
+	filterMutex.Lock()
+	defer filterMutex.Unlock()
+	delete(filterRegistry, name)
+}
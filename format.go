@@ -0,0 +1,230 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	formatVerbs       = "vTtbcdoOqxXUeEfFgGsp"
+	formatFlags       = "+-# 0"
+	formatIntVerbs    = "cdoOU"
+	formatFloatVerbs  = "eEfFgG"
+	formatStringVerbs = "sq"
+	formatBoolVerbs   = "t"
+)
+
+type formatVerb struct {
+	verb     rune
+	argIndex int // zero based index of argument
+}
+
+func (f formatVerb) String() string {
+	return fmt.Sprintf("%%%c(arg=%d)", f.verb, f.argIndex)
+}
+
+// formatSpec is verbs declared in format text and the number of arguments it needs
+type formatSpec struct {
+	verbs []formatVerb
+	arity int
+	err   error
+}
+
+// parseFormatSpec parses verbs of fmt format text. explicit argument index(%[2]s) and '*' width are supported
+// e.g) "hello %s. level %03d (100%%)" => [s d], arity 2
+func parseFormatSpec(text string) formatSpec {
+	spec := formatSpec{verbs: make([]formatVerb, 0)}
+	argNum := 0
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' {
+			continue
+		}
+
+		i++
+		for i < len(runes) && strings.ContainsRune(formatFlags, runes[i]) {
+			i++
+		}
+
+		// width, precision and argument index
+		for i < len(runes) {
+			switch {
+			case runes[i] == '[':
+				end := i + 1
+				for end < len(runes) && runes[end] != ']' {
+					end++
+				}
+				if end >= len(runes) {
+					spec.err = fmt.Errorf("incompleted argument index")
+					return spec
+				}
+				n, err := strconv.Atoi(string(runes[i+1 : end]))
+				if err != nil || n < 1 {
+					spec.err = fmt.Errorf("invalid argument index %s", string(runes[i:end+1]))
+					return spec
+				}
+				argNum = n - 1
+				i = end + 1
+				continue
+			case runes[i] == '*':
+				spec.verbs = append(spec.verbs, formatVerb{verb: '*', argIndex: argNum})
+				argNum++
+				i++
+				continue
+			case runes[i] == '.' || (runes[i] >= '0' && runes[i] <= '9'):
+				i++
+				continue
+			}
+			break
+		}
+
+		if i >= len(runes) {
+			spec.err = fmt.Errorf("incompleted verb at end of text")
+			return spec
+		}
+		if runes[i] == '%' {
+			continue
+		}
+		if !strings.ContainsRune(formatVerbs, runes[i]) {
+			spec.err = fmt.Errorf("invalid verb %%%c", runes[i])
+			return spec
+		}
+
+		spec.verbs = append(spec.verbs, formatVerb{verb: runes[i], argIndex: argNum})
+		argNum++
+	}
+
+	for _, v := range spec.verbs {
+		if v.argIndex+1 > spec.arity {
+			spec.arity = v.argIndex + 1
+		}
+	}
+	return spec
+}
+
+const (
+	formatArgInt    = "int"
+	formatArgFloat  = "float"
+	formatArgString = "string"
+	formatArgBool   = "bool"
+)
+
+// argClasses returns kind of each argument required by verbs. empty for verb accepting any value (%v, %x).
+// fails when an argument is used with verbs of different kind. e.g) "%[1]d %[1]s"
+func (f formatSpec) argClasses() ([]string, error) {
+	classes := make([]string, f.arity)
+	for _, v := range f.verbs {
+		class := verbClass(v.verb)
+		if len(class) == 0 {
+			continue
+		}
+		prev := classes[v.argIndex]
+		if len(prev) > 0 && prev != class {
+			return nil, fmt.Errorf("argument %d is used as %s and %s", v.argIndex+1, prev, class)
+		}
+		classes[v.argIndex] = class
+	}
+	return classes, nil
+}
+
+func verbClass(verb rune) string {
+	switch {
+	case verb == '*':
+		return formatArgInt
+	case strings.ContainsRune(formatIntVerbs, verb):
+		return formatArgInt
+	case strings.ContainsRune(formatFloatVerbs, verb):
+		return formatArgFloat
+	case strings.ContainsRune(formatStringVerbs, verb):
+		return formatArgString
+	case strings.ContainsRune(formatBoolVerbs, verb):
+		return formatArgBool
+	}
+	return ""
+}
+
+// check verifies arguments against verbs. verb kinds are compared only in strict mode
+func (f formatSpec) check(args []interface{}, strict bool) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	if len(args) != f.arity {
+		return fmt.Errorf("expect %d format arguments but %d given", f.arity, len(args))
+	}
+
+	if !strict {
+		return nil
+	}
+
+	for _, v := range f.verbs {
+		if !verbAccepts(v.verb, args[v.argIndex]) {
+			return fmt.Errorf("argument %d (%v) does not match verb %%%c", v.argIndex+1, reflect.TypeOf(args[v.argIndex]), v.verb)
+		}
+	}
+	return nil
+}
+
+func verbAccepts(verb rune, arg interface{}) bool {
+	switch arg.(type) {
+	case fmt.Formatter:
+		return true
+	case fmt.Stringer, error:
+		if strings.ContainsRune(formatStringVerbs, verb) {
+			return true
+		}
+	}
+
+	kind := reflect.Invalid
+	if arg != nil {
+		kind = reflect.TypeOf(arg).Kind()
+	}
+
+	switch {
+	case verb == '*':
+		return kind == reflect.Int
+	case strings.ContainsRune(formatIntVerbs, verb):
+		return isIntKind(kind)
+	case strings.ContainsRune(formatFloatVerbs, verb):
+		return kind == reflect.Float32 || kind == reflect.Float64 || kind == reflect.Complex64 || kind == reflect.Complex128
+	case strings.ContainsRune(formatStringVerbs, verb):
+		return kind == reflect.String || (kind == reflect.Slice && reflect.TypeOf(arg).Elem().Kind() == reflect.Uint8) ||
+			(verb == 'q' && isIntKind(kind))
+	case strings.ContainsRune(formatBoolVerbs, verb):
+		return kind == reflect.Bool
+	}
+	return true
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
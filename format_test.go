@@ -0,0 +1,80 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormatSpec(t *testing.T) {
+	spec := parseFormatSpec("hello %s. your level is %03d (100%%)")
+	assert.Nil(t, spec.err)
+	assert.Equal(t, 2, spec.arity)
+	assert.Equal(t, 's', spec.verbs[0].verb)
+	assert.Equal(t, 'd', spec.verbs[1].verb)
+
+	spec = parseFormatSpec("%[2]s님 %[1]d매 %[2]s")
+	assert.Nil(t, spec.err)
+	assert.Equal(t, 2, spec.arity)
+
+	spec = parseFormatSpec("%*d %.2f")
+	assert.Nil(t, spec.err)
+	assert.Equal(t, 3, spec.arity)
+
+	assert.NotNil(t, parseFormatSpec("hello %").err)
+	assert.NotNil(t, parseFormatSpec("hello %z").err)
+	assert.NotNil(t, parseFormatSpec("hello %[0]s").err)
+}
+
+func TestFormatSpecCheck(t *testing.T) {
+	spec := parseFormatSpec("hello %s. your level is %d")
+	assert.Nil(t, spec.check([]interface{}{"fatima", 4}, true))
+	assert.NotNil(t, spec.check([]interface{}{"fatima"}, false))
+	assert.NotNil(t, spec.check([]interface{}{"fatima", 4, 5}, false))
+
+	assert.Nil(t, spec.check([]interface{}{"fatima", "4"}, false))
+	assert.NotNil(t, spec.check([]interface{}{"fatima", "4"}, true))
+}
+
+func TestFormatArgumentMismatch(t *testing.T) {
+	man := newStringMan(NewStringmanPreference(""))
+	err := man.registStatement(QueryStatement{Id: "Greeting", Query: "hello %s. your level is %d"})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	_, err = man.FormatWithStmt("greeting", "fatima")
+	assert.NotNil(t, err)
+
+	built, err := man.FormatWithStmt("greeting", "fatima", 4)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello fatima. your level is 4", built)
+
+	pref := NewStringmanPreference("")
+	pref.StrictFormat = true
+	strict := newStringMan(pref)
+	err = strict.registStatement(QueryStatement{Id: "Broken", Query: "hello %z"})
+	assert.NotNil(t, err)
+}
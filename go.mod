@@ -2,4 +2,7 @@ module github.com/fatima-go/stringman
 
 go 1.16
 
-require github.com/stretchr/testify v1.9.0
+require (
+	github.com/stretchr/testify v1.9.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,238 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const httpSourceTimeout = 10 * time.Second
+
+// HttpSource fetches statement bundle (xml, json) from url. fetched bundle is cached to file for cold start
+// and refreshed with ETag (If-None-Match)
+type HttpSource struct {
+	layer     string
+	url       string
+	cacheFile string
+	client    *http.Client
+	mutex     sync.Mutex
+	bundle    httpBundle  // adopted bundle. its etag is sent and it is cached
+	pending   *httpBundle // fetched bundle waiting for catalog to be replaced
+}
+
+// httpBundle is fetched bundle. also written to cache file as json
+type httpBundle struct {
+	Name string `json:"name"` // file name for choosing parser. e.g) string.json
+	ETag string `json:"etag,omitempty"`
+	Data string `json:"data"`
+}
+
+// NewHttpSource returns source of bundle served at rawUrl. cacheFile could be empty for no cache
+func NewHttpSource(layer string, rawUrl string, cacheFile string) (*HttpSource, error) {
+	_, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	source := &HttpSource{layer: layer, url: rawUrl, cacheFile: cacheFile}
+	source.client = &http.Client{Timeout: httpSourceTimeout}
+	return source, nil
+}
+
+func (s *HttpSource) Layer() string {
+	return s.layer
+}
+
+// Load returns statements of latest bundle. cached bundle is used when server is not available at cold start.
+// fetched bundle is adopted by commit after catalog is replaced
+func (s *HttpSource) Load() ([]QueryStatement, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.pending == nil {
+		_, err := s.fetch()
+		if err != nil && len(s.bundle.Name) == 0 {
+			cacheErr := s.readCache()
+			if cacheErr != nil {
+				return nil, fmt.Errorf("fail to fetch %s : %s (cache : %s)", s.url, err.Error(), cacheErr.Error())
+			}
+		}
+	}
+
+	bundle := s.bundle
+	if s.pending != nil {
+		bundle = *s.pending
+	}
+	return ParseStatements(bundle.Name, []byte(bundle.Data))
+}
+
+// Changed fetches bundle with If-None-Match and reports whether new bundle is served
+func (s *HttpSource) Changed() (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.fetch()
+}
+
+func (s *HttpSource) fetch() (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return false, err
+	}
+	if len(s.bundle.ETag) > 0 && len(s.bundle.Name) > 0 {
+		req.Header.Set("If-None-Match", s.bundle.ETag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	name, err := s.bundleName(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return false, err
+	}
+
+	// etag and cache are not changed until bundle is registered. see commit
+	s.pending = &httpBundle{Name: name, ETag: resp.Header.Get("ETag"), Data: string(data)}
+	return true, nil
+}
+
+// commit adopts pending bundle after catalog is replaced with it and writes it to cache
+func (s *HttpSource) commit() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.pending == nil {
+		return nil
+	}
+	s.bundle = *s.pending
+	s.pending = nil
+
+	err := s.writeCache()
+	if err != nil {
+		return fmt.Errorf("fail to write cache %s : %s", s.cacheFile, err.Error())
+	}
+	return nil
+}
+
+// discard drops pending bundle which fails to load. it is fetched again with etag of adopted bundle
+func (s *HttpSource) discard() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending = nil
+}
+
+// bundleName returns file name of url path if it has supported extension or name by content type
+func (s *HttpSource) bundleName(contentType string) (string, error) {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return "", err
+	}
+
+	name := path.Base(u.Path)
+	if _, ok := findStatementParser(name); ok {
+		return name, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return "bundle" + extJson, nil
+	case "application/xml", "text/xml":
+		return "bundle" + extXml, nil
+	}
+	return "", fmt.Errorf("unsupported bundle %s (content-type=%s)", s.url, contentType)
+}
+
+func (s *HttpSource) readCache() error {
+	if len(s.cacheFile) == 0 {
+		return fmt.Errorf("no cache file")
+	}
+
+	data, err := ioutil.ReadFile(s.cacheFile)
+	if err != nil {
+		return err
+	}
+
+	bundle := httpBundle{}
+	err = json.Unmarshal(data, &bundle)
+	if err != nil {
+		return err
+	}
+
+	s.bundle = bundle
+	return nil
+}
+
+// writeCache writes bundle to temporary file and renames it to cache file
+func (s *HttpSource) writeCache() error {
+	if len(s.cacheFile) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(s.bundle)
+	if err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempFile(filepath.Dir(s.cacheFile), filepath.Base(s.cacheFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), s.cacheFile)
+}
@@ -0,0 +1,274 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bundleServer serves json bundle with ETag
+type bundleServer struct {
+	mutex       sync.Mutex
+	version     int
+	text        string
+	broken      bool
+	xml         string // served as xml bundle when not empty
+	notModified int
+}
+
+func (b *bundleServer) update(text string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.version++
+	b.text = text
+}
+
+func (b *bundleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	etag := fmt.Sprintf(`"v%d"`, b.version)
+	if r.Header.Get("If-None-Match") == etag {
+		b.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	if len(b.xml) > 0 {
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprint(w, b.xml)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if b.broken {
+		fmt.Fprint(w, `{"statements": [`)
+		return
+	}
+	fmt.Fprintf(w, `{"statements": [{"id": "Greeting", "text": %q}]}`, b.text)
+}
+
+func TestHttpSource(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-http")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	cacheFile := filepath.Join(dir, "bundle.cache")
+
+	bundle := &bundleServer{}
+	bundle.update("안녕하세요 %s")
+	server := httptest.NewServer(bundle)
+
+	source, err := NewHttpSource("config", server.URL+"/bundles/message", cacheFile)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	pref := NewStringmanPreference("")
+	pref.Fileset = "none"
+	pref.Sources = []Source{source}
+	man, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer man.Close()
+
+	text, err := man.FormatWithStmt("Greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "안녕하세요 fatima", text)
+
+	changed, err := source.Changed()
+	assert.Nil(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 1, bundle.notModified)
+
+	bundle.update("반갑습니다 %s")
+	assert.Nil(t, man.loader.refresh())
+	text, err = man.FormatWithStmt("Greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "반갑습니다 fatima", text)
+
+	// broken bundle is neither adopted nor cached
+	bundle.mutex.Lock()
+	bundle.broken = true
+	bundle.mutex.Unlock()
+	bundle.update("broken %s")
+	assert.NotNil(t, man.loader.refresh())
+	assert.Equal(t, `"v2"`, source.bundle.ETag)
+	text, err = man.FormatWithStmt("Greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "반갑습니다 fatima", text)
+
+	// cold start from cache while server is down
+	server.Close()
+	cold, err := NewHttpSource("config", server.URL+"/bundles/message", cacheFile)
+	if !assert.Nil(t, err) {
+		return
+	}
+	pref.Sources = []Source{cold}
+	man, err = NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer man.Close()
+
+	text, err = man.FormatWithStmt("Greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "반갑습니다 fatima", text)
+
+	assert.NotNil(t, man.loader.refresh())
+	text, err = man.FormatWithStmt("Greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "반갑습니다 fatima", text)
+
+	noCache, _ := NewHttpSource("config", server.URL+"/bundles/message", "")
+	pref.Sources = []Source{noCache}
+	_, err = NewStringman(pref)
+	assert.NotNil(t, err)
+}
+
+func (b *bundleServer) updateXml(xml string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.version++
+	b.xml = xml
+}
+
+// flakySource fails to load while fail is set
+type flakySource struct {
+	mutex sync.Mutex
+	fail  bool
+}
+
+func (s *flakySource) Layer() string {
+	return "flaky"
+}
+
+func (s *flakySource) Load() ([]QueryStatement, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.fail {
+		return nil, fmt.Errorf("temporarily unavailable")
+	}
+	return []QueryStatement{}, nil
+}
+
+func (s *flakySource) setFail(fail bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.fail = fail
+}
+
+// bundle which fails to load is not adopted. etag and cache are kept so it is fetched again on next refresh
+func TestHttpSourceNotAdoptedUntilRegistered(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-http")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	cacheFile := filepath.Join(dir, "bundle.cache")
+
+	bundle := &bundleServer{}
+	bundle.updateXml(`<query><text id="Greeting">hello %s</text></query>`)
+	server := httptest.NewServer(bundle)
+	defer server.Close()
+
+	source, err := NewHttpSource("config", server.URL+"/bundles/message", cacheFile)
+	if !assert.Nil(t, err) {
+		return
+	}
+	flaky := &flakySource{}
+
+	pref := NewStringmanPreference("")
+	pref.Fileset = "none"
+	pref.Sources = []Source{source, flaky}
+	man, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer man.Close()
+
+	assertGreeting := func(expect string) {
+		text, err := man.FormatWithStmt("Greeting", "fatima")
+		assert.Nil(t, err)
+		assert.Equal(t, expect, text)
+	}
+	assertGreeting("hello fatima")
+
+	// truncated bundle returns error instead of panic in refresh goroutine
+	bundle.updateXml(`<query><text id="Greeting">ab`)
+	assert.NotNil(t, man.loader.refresh())
+	assert.Equal(t, `"v1"`, source.bundle.ETag)
+	assertGreeting("hello fatima")
+
+	// bundle which parses but fails to register is fetched again
+	bundle.updateXml(`<query><text id="Greeting">ab</text></query>`)
+	assert.NotNil(t, man.loader.refresh())
+	assert.NotNil(t, man.loader.refresh())
+	assert.Equal(t, `"v1"`, source.bundle.ETag)
+	assertGreeting("hello fatima")
+
+	bundle.updateXml(`<query><text id="Greeting">hi %s</text></query>`)
+	assert.Nil(t, man.loader.refresh())
+	assert.Equal(t, `"v4"`, source.bundle.ETag)
+	assertGreeting("hi fatima")
+
+	// transient failure of other source does not move etag
+	flaky.setFail(true)
+	bundle.updateXml(`<query><text id="Greeting">bye %s</text></query>`)
+	assert.NotNil(t, man.loader.refresh())
+	assert.Equal(t, `"v4"`, source.bundle.ETag)
+	assertGreeting("hi fatima")
+
+	flaky.setFail(false)
+	assert.Nil(t, man.loader.refresh())
+	assert.Equal(t, `"v5"`, source.bundle.ETag)
+	assertGreeting("bye fatima")
+
+	// cache holds last registered bundle
+	bundle.updateXml(`<query><text id="Greeting">ab</text></query>`)
+	assert.NotNil(t, man.loader.refresh())
+	server.Close()
+	cold, err := NewHttpSource("config", server.URL+"/bundles/message", cacheFile)
+	if !assert.Nil(t, err) {
+		return
+	}
+	pref.Sources = []Source{cold}
+	coldMan, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer coldMan.Close()
+	text, err := coldMan.FormatWithStmt("Greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "bye fatima", text)
+}
@@ -0,0 +1,120 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var layerBaseXml = []byte(`
+<query>
+	<text id="SelectSampleMembers">
+		SELECT * FROM MEMBER LIMIT {Limit=100}
+	</text>
+	<text id="SelectCity">
+		SELECT * FROM CITY
+	</text>
+</query>
+`)
+
+var layerStagingXml = []byte(`
+<query>
+	<text id="SelectSampleMembers" override="true">
+		SELECT * FROM MEMBER WHERE TEST = 'Y' LIMIT {Limit=10}
+	</text>
+</query>
+`)
+
+func prepareLayers(t *testing.T) (string, string) {
+	dir, err := os.MkdirTemp("", "stringman-layer")
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+
+	base := filepath.Join(dir, "base")
+	staging := filepath.Join(dir, "staging")
+	assert.Nil(t, os.Mkdir(base, 0755))
+	assert.Nil(t, os.Mkdir(staging, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(base, "string.xml"), layerBaseXml, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(staging, "string.xml"), layerStagingXml, 0644))
+	return dir, base
+}
+
+func TestLayerOverride(t *testing.T) {
+	dir, base := prepareLayers(t)
+	defer os.RemoveAll(dir)
+
+	pref := NewStringmanPreference(base)
+	pref.Layers = []StatementLayer{{Name: "staging", Path: filepath.Join(dir, "staging")}}
+	man, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	query, err := man.BuildWithStmt("SelectSampleMembers", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM MEMBER WHERE TEST = 'Y' LIMIT 10", query)
+
+	stmt, err := man.find("SelectSampleMembers")
+	assert.Nil(t, err)
+	assert.Equal(t, "staging", stmt.Layer())
+
+	stmt, err = man.find("SelectCity")
+	assert.Nil(t, err)
+	assert.Equal(t, baseLayerName, stmt.Layer())
+
+	issues, err := Lint(pref)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(issues))
+}
+
+func TestLayerDuplicatedWithoutOverride(t *testing.T) {
+	dir, base := prepareLayers(t)
+	defer os.RemoveAll(dir)
+
+	staging := filepath.Join(dir, "staging")
+	assert.Nil(t, os.WriteFile(filepath.Join(staging, "string.xml"), layerBaseXml, 0644))
+
+	pref := NewStringmanPreference(base)
+	pref.Layers = []StatementLayer{{Name: "staging", Path: staging}}
+	_, err := NewStringman(pref)
+	assert.NotNil(t, err)
+
+	issues, err := Lint(pref)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(issues))
+}
+
+func TestLayerDuplicatedInSameLayer(t *testing.T) {
+	dir, base := prepareLayers(t)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(base, "string.staging.xml"), layerStagingXml, 0644))
+
+	_, err := NewStringman(NewStringmanPreference(base))
+	assert.NotNil(t, err)
+}
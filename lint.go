@@ -0,0 +1,312 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	LintRuleDuplicatedId     = "duplicated-id"
+	LintRuleInvalidStatement = "invalid-statement"
+	LintRuleInvalidVerb      = "invalid-verb"
+	LintRuleMismatchedVerb   = "mismatched-verb"
+	LintRuleUnusedFragment   = "unused-fragment"
+	LintRuleUnknownElement   = "unknown-element"
+	LintRuleUnknownAttribute = "unknown-attribute"
+	LintRuleMissingId        = "missing-id"
+	LintRuleInvalidXml       = "invalid-xml"
+	LintRuleInvalidFile      = "invalid-file"
+)
+
+// LintIssue is a problem found in statement file
+type LintIssue struct {
+	File    string `json:"file"`
+	Id      string `json:"id,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (l LintIssue) String() string {
+	if len(l.Id) == 0 {
+		return fmt.Sprintf("%s: [%s] %s", l.File, l.Rule, l.Message)
+	}
+	return fmt.Sprintf("%s: %s: [%s] %s", l.File, l.Id, l.Rule, l.Message)
+}
+
+// knownAttributes lists attributes allowed for each element in statement file
+var knownAttributes = map[string][]string{
+	"query":    {},
+	"text":     {attrId, attrEngine, attrLang, attrOverride},
+	"allow":    {attrParam},
+	"fragment": {attrId},
+	"include":  {attrRef},
+}
+
+// Lint loads statement files of every layer with pref like NewStringman but collects every problem instead of stopping at first one
+func Lint(pref StringmanPreference) ([]LintIssue, error) {
+	issues := make([]LintIssue, 0)
+	declared := make(map[string]QueryStatement)
+	for _, layer := range pref.layers() {
+		files, err := findStatementFiles(layer.Path, layer.Fileset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			fileIssues, err := lintFile(file, layer.Name, pref.FileLocales, declared)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, fileIssues...)
+		}
+	}
+
+	return append(issues, lintLocaleVerbs(declared)...), nil
+}
+
+// lintFile checks statements of file. declared keeps statements of previous files for duplication check
+func lintFile(file string, layer string, locales []string, declared map[string]QueryStatement) ([]LintIssue, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read file[%s] : %s", file, err.Error())
+	}
+
+	issues := make([]LintIssue, 0)
+	invalidRule := LintRuleInvalidFile
+	if strings.EqualFold(filepath.Ext(file), extXml) {
+		invalidRule = LintRuleInvalidXml
+		issues = append(issues, lintElements(file, data)...)
+	}
+
+	list, err := parseStatementFile(file, data)
+	if err != nil {
+		return append(issues, LintIssue{File: file, Rule: invalidRule, Message: err.Error()}), nil
+	}
+
+	for _, stmt := range list {
+		if len(stmt.Id) == 0 {
+			continue
+		}
+		if len(stmt.lang) == 0 {
+			stmt.lang = fileLocale(file, locales)
+		}
+		stmt.source = file
+		stmt.layer = layer
+		id := strings.ToUpper(stmt.Id) + "@" + stmt.lang
+		prev, exists := declared[id]
+		if exists && (!stmt.override || prev.layer == stmt.layer) {
+			issues = append(issues, LintIssue{File: file, Id: stmt.Id, Rule: LintRuleDuplicatedId,
+				Message: fmt.Sprintf("already declared in %s", prev.source)})
+		} else {
+			declared[id] = stmt
+		}
+		issues = append(issues, lintStatement(file, stmt)...)
+	}
+
+	return issues, nil
+}
+
+func lintStatement(file string, stmt QueryStatement) []LintIssue {
+	issues := make([]LintIssue, 0)
+	if len(stmt.engine) > 0 {
+		err := newStringMan(NewStringmanPreference("")).registStatement(stmt)
+		if err != nil {
+			issues = append(issues, LintIssue{File: file, Id: stmt.Id, Rule: LintRuleInvalidStatement, Message: err.Error()})
+		}
+		return issues
+	}
+
+	err := newNormalizer().normalize(&stmt)
+	if err != nil {
+		return append(issues, LintIssue{File: file, Id: stmt.Id, Rule: LintRuleInvalidStatement, Message: err.Error()})
+	}
+
+	// statement without variable is format text. '%' in build statement could be sql like pattern
+	if len(stmt.columnMention) == 0 {
+		spec := parseFormatSpec(stmt.Query)
+		if spec.err != nil {
+			return append(issues, LintIssue{File: file, Id: stmt.Id, Rule: LintRuleInvalidVerb, Message: spec.err.Error()})
+		}
+		if _, err := spec.argClasses(); err != nil {
+			issues = append(issues, LintIssue{File: file, Id: stmt.Id, Rule: LintRuleMismatchedVerb, Message: err.Error()})
+		}
+	}
+
+	return issues
+}
+
+// lintLocaleVerbs checks locale variants of format text take same arguments.
+// variants are compared with statement without locale, or first locale in order
+func lintLocaleVerbs(declared map[string]QueryStatement) []LintIssue {
+	variants := make(map[string][]QueryStatement)
+	for _, stmt := range declared {
+		if len(stmt.engine) > 0 {
+			continue
+		}
+		id := strings.ToUpper(stmt.Id)
+		variants[id] = append(variants[id], stmt)
+	}
+
+	ids := make([]string, 0, len(variants))
+	for id, list := range variants {
+		if len(list) > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	issues := make([]LintIssue, 0)
+	for _, id := range ids {
+		list := variants[id]
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].lang < list[j].lang
+		})
+
+		base, baseClasses, ok := formatArgClasses(list[0])
+		if !ok {
+			continue
+		}
+		for _, stmt := range list[1:] {
+			_, classes, ok := formatArgClasses(stmt)
+			if !ok {
+				continue
+			}
+			if msg := compareArgClasses(baseClasses, classes); len(msg) > 0 {
+				issues = append(issues, LintIssue{File: stmt.source, Id: stmt.Id, Rule: LintRuleMismatchedVerb,
+					Message: fmt.Sprintf("%s in %s (%s)", msg, localeName(base.lang), base.source)})
+			}
+		}
+	}
+	return issues
+}
+
+// formatArgClasses returns argument classes of format text. false for build statement or invalid format
+func formatArgClasses(stmt QueryStatement) (QueryStatement, []string, bool) {
+	err := newNormalizer().normalize(&stmt)
+	if err != nil || len(stmt.columnMention) > 0 {
+		return stmt, nil, false
+	}
+	spec := parseFormatSpec(stmt.Query)
+	if spec.err != nil {
+		return stmt, nil, false
+	}
+	classes, err := spec.argClasses()
+	if err != nil {
+		return stmt, nil, false
+	}
+	return stmt, classes, true
+}
+
+func compareArgClasses(base []string, classes []string) string {
+	if len(base) != len(classes) {
+		return fmt.Sprintf("takes %d arguments but %d", len(classes), len(base))
+	}
+	for i := range base {
+		if len(base[i]) > 0 && len(classes[i]) > 0 && base[i] != classes[i] {
+			return fmt.Sprintf("argument %d is %s but %s", i+1, classes[i], base[i])
+		}
+	}
+	return ""
+}
+
+func localeName(lang string) string {
+	if len(lang) == 0 {
+		return "default statement"
+	}
+	return lang
+}
+
+func lintElements(file string, data []byte) []LintIssue {
+	issues := make([]LintIssue, 0)
+	fragments := make([]string, 0)
+	included := make(map[string]bool)
+	dec := xml.NewDecoder(bytes.NewBuffer(data))
+	for {
+		t, tokenErr := dec.Token()
+		if tokenErr != nil {
+			if tokenErr != io.EOF {
+				return append(issues, LintIssue{File: file, Rule: LintRuleInvalidXml, Message: tokenErr.Error()})
+			}
+			// fragment is visible only in its file
+			for _, id := range fragments {
+				if !included[id] {
+					issues = append(issues, LintIssue{File: file, Id: id, Rule: LintRuleUnusedFragment,
+						Message: "fragment is not included by any statement"})
+				}
+			}
+			return issues
+		}
+
+		e, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		known, ok := knownAttributes[e.Name.Local]
+		if !ok {
+			issues = append(issues, LintIssue{File: file, Rule: LintRuleUnknownElement,
+				Message: fmt.Sprintf("unknown element <%s>", e.Name.Local)})
+			continue
+		}
+
+		id := getAttr(e.Attr, attrId)
+		switch buildElementType(e.Name.Local) {
+		case eleTypeText:
+			if len(id) == 0 {
+				issues = append(issues, LintIssue{File: file, Rule: LintRuleMissingId, Message: "text element without id"})
+			}
+		case eleTypeFragment:
+			if len(id) == 0 {
+				issues = append(issues, LintIssue{File: file, Rule: LintRuleMissingId, Message: "fragment element without id"})
+			} else {
+				fragments = append(fragments, id)
+			}
+		case eleTypeInclude:
+			included[getAttr(e.Attr, attrRef)] = true
+		}
+
+		for _, attr := range e.Attr {
+			if !containsString(known, attr.Name.Local) {
+				issues = append(issues, LintIssue{File: file, Id: id, Rule: LintRuleUnknownAttribute,
+					Message: fmt.Sprintf("unknown attribute %s in <%s>", attr.Name.Local, e.Name.Local)})
+			}
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
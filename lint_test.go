@@ -0,0 +1,109 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var lintXmlFirst = []byte(`
+<?xml version="1.0" encoding="UTF-8" ?>
+<query>
+	<text id="SelectCity">
+		SELECT * FROM CITY WHERE NAME={Name}
+	</text>
+	<text id="BrokenVariable">
+		SELECT * FROM CITY WHERE NAME={Name
+	</text>
+	<text id="Short">
+		ab
+	</text>
+	<text id="BadVerb" type="message">
+		hello %z
+	</text>
+	<txt id="Unknown">
+		hello
+	</txt>
+	<fragment id="cityColumns">id, name</fragment>
+	<fragment id="unusedColumns">id, age</fragment>
+	<text id="SelectCityColumns">
+		SELECT <include ref="cityColumns"/> FROM CITY
+	</text>
+	<text id="MixedVerb">
+		%[1]d items for %[1]s
+	</text>
+	<text id="Greeting">
+		hello %s. your level is %d
+	</text>
+</query>
+`)
+
+var lintXmlSecond = []byte(`
+<?xml version="1.0" encoding="UTF-8" ?>
+<query>
+	<text id="selectCity">
+		SELECT * FROM CITY
+	</text>
+	<text id="Greeting" lang="ko">
+		%d 레벨 %s 님 안녕하세요
+	</text>
+</query>
+`)
+
+func TestLint(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-lint")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.a.xml"), lintXmlFirst, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.b.xml"), lintXmlSecond, 0644))
+
+	issues, err := Lint(NewStringmanPreference(dir))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	rules := make(map[string]string)
+	for _, v := range issues {
+		rules[v.Rule+":"+v.Id] = filepath.Base(v.File)
+	}
+
+	assert.Equal(t, "string.b.xml", rules[LintRuleDuplicatedId+":selectCity"])
+	assert.Contains(t, rules, LintRuleInvalidStatement+":BrokenVariable")
+	assert.Contains(t, rules, LintRuleInvalidStatement+":Short")
+	assert.Contains(t, rules, LintRuleInvalidVerb+":BadVerb")
+	assert.Contains(t, rules, LintRuleUnknownAttribute+":BadVerb")
+	assert.Contains(t, rules, LintRuleUnknownElement+":")
+	assert.NotContains(t, rules, LintRuleInvalidStatement+":SelectCity")
+	assert.Contains(t, rules, LintRuleUnusedFragment+":unusedColumns")
+	assert.NotContains(t, rules, LintRuleUnusedFragment+":cityColumns")
+	assert.NotContains(t, rules, LintRuleInvalidStatement+":SelectCityColumns")
+	assert.Contains(t, rules, LintRuleMismatchedVerb+":MixedVerb")
+	assert.Equal(t, "string.b.xml", rules[LintRuleMismatchedVerb+":Greeting"])
+}
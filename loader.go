@@ -27,11 +27,12 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Logger interface {
@@ -52,6 +53,7 @@ func NewStringmanPreference(filepath string) StringmanPreference {
 	pref.fieldNameConvert = fieldNameConvertToCamel
 	pref.Debug = false
 	pref.DebugLogger = defaultLogger{}
+	pref.SetDialect(DialectMySQL)
 
 	return pref
 }
@@ -62,18 +64,72 @@ type StringmanPreference struct {
 	fieldNameConvert fieldNameConvertMethod
 	Debug            bool
 	DebugLogger      Logger
+	dialect          Dialect
+	LiteralFormat    LiteralFormat
+	CallerNameMode   CallerNameMode
+	DefaultLocale    string               // locale of statement without lang. used for plural rules
+	FileLocales      []string             // locales of per-locale files. string.en.xml is loaded as en only when en is listed
+	LocaleFallback   []LocaleFallbackRule // explicit fallback chain searched in order. e.g) zh-HK => [zh-TW, zh]
+	StrictFormat     bool                 // fail loading invalid format text and check verb kinds of Format arguments
+	Layers           []StatementLayer     // loaded after base path in order. e.g) staging overrides
+	Sources          []Source             // loaded after Layers in order. e.g) database
+	RefreshInterval  time.Duration        // interval of checking RefreshableSource. no refresh if zero
+	UnusedParamMode  UnusedParamMode      // handling of parameter not declared in statement
+	ParamMatchMode   ParamMatchMode       // matching parameter name with variable name
 }
 
-func NewStringman(pref StringmanPreference) (*StringMan, error) {
-	manager := &StringMan{}
-	manager.preference = pref
-	manager.statementMap = make(map[string]QueryStatement)
+const baseLayerName = "base"
 
-	manager.fieldNameConverter = newFieldNameConverter(pref.fieldNameConvert)
+// StatementLayer is a set of statement files loaded after base path.
+// statement of later layer replaces same id of earlier layer only when it is declared with override="true"
+type StatementLayer struct {
+	Name    string
+	Path    string
+	Fileset string // Fileset of preference is used if empty
+}
+
+// sources returns file sources of layers followed by Sources
+func (p StringmanPreference) sources() []Source {
+	sources := make([]Source, 0)
+	for _, v := range p.layers() {
+		sources = append(sources, NewFileSource(v.Name, v.Path, v.Fileset))
+	}
+	return append(sources, p.Sources...)
+}
+
+// layers returns base path as first layer followed by Layers
+func (p StringmanPreference) layers() []StatementLayer {
+	layers := []StatementLayer{{Name: baseLayerName, Path: p.queryFilePath, Fileset: p.Fileset}}
+	for _, v := range p.Layers {
+		if len(v.Fileset) == 0 {
+			v.Fileset = p.Fileset
+		}
+		if len(v.Name) == 0 {
+			v.Name = v.Path
+		}
+		layers = append(layers, v)
+	}
+	return layers
+}
+
+// SetDialect changes dialect and resets LiteralFormat to the dialect default
+func (p *StringmanPreference) SetDialect(dialect Dialect) {
+	p.dialect = dialect
+	p.LiteralFormat = dialect.LiteralFormat()
+}
 
-	err := loadXmlFile(manager, pref.queryFilePath, pref.Fileset)
+func NewStringman(pref StringmanPreference) (*StringMan, error) {
+	manager := newStringMan(pref)
+	manager.loader.sources = pref.sources()
+
+	err := manager.Reload()
 	if err != nil {
-		return nil, fmt.Errorf("fail to load xml file : %s [path=%s,fileset=%s]", err.Error(), pref.queryFilePath, pref.Fileset)
+		return nil, err
+	}
+
+	// refresh goroutine refers loader only. manager without reference is finalized and stops it
+	if pref.RefreshInterval > 0 {
+		go manager.loader.refreshLoop(pref.RefreshInterval, manager.stopRefresh)
 	}
 
 	runtime.SetFinalizer(manager, closeStringman)
@@ -81,6 +137,18 @@ func NewStringman(pref StringmanPreference) (*StringMan, error) {
 	return manager, nil
 }
 
+func newStringMan(pref StringmanPreference) *StringMan {
+	manager := &StringMan{}
+	manager.preference = pref
+	manager.fieldNameConverter = newFieldNameConverter(pref.fieldNameConvert)
+	manager.encoders = newEncoderRegistry(pref.LiteralFormat)
+	manager.loader = newStatementLoader(pref, manager.encoders)
+	manager.callerCache = &sync.Map{}
+	manager.stopRefresh = make(chan struct{})
+	manager.closeOnce = &sync.Once{}
+	return manager
+}
+
 func newFieldNameConverter(fieldNameConvert fieldNameConvertMethod) FieldNameConvertStrategy {
 	switch fieldNameConvert {
 	case fieldNameConvertToUnderstore:
@@ -94,121 +162,220 @@ func closeStringman(manager *StringMan) {
 	manager.Close()
 }
 
-func loadXmlFile(manager *StringMan, filePath string, fileSet string) error {
+// findStatementFiles returns files matched with fileSet which have supported extension (xml, yaml, json, sql)
+func findStatementFiles(filePath string, fileSet string) ([]string, error) {
 	var buffer bytes.Buffer
 	buffer.WriteString(filePath)
 	buffer.WriteRune(filepath.Separator)
 	buffer.WriteString(fileSet)
 	matches, err := filepath.Glob(buffer.String())
 	if err != nil {
-		return fmt.Errorf("fail to search xml file : %s [glob=%s]", err.Error(), buffer.String())
+		return nil, fmt.Errorf("fail to search statement file : %s [glob=%s]", err.Error(), buffer.String())
 	}
 
-	if manager.preference.Debug {
-		manager.preference.DebugLogger.Printf("matches len=%d", len(matches))
-	}
+	return filterStatementFiles(matches), nil
+}
 
+func filterStatementFiles(matches []string) []string {
+	files := make([]string, 0, len(matches))
 	for _, file := range matches {
-		if !strings.HasSuffix(file, "xml") {
+		if _, ok := findStatementParser(file); !ok {
 			continue
 		}
-
-		data, err := ioutil.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("fail to read file[%s] : %s", file, err.Error())
-		}
-
-		err = loadWithSax(manager, data)
-		if err != nil {
-			return err
-		}
+		files = append(files, file)
 	}
+	return files
+}
 
-	return nil
+// saxParser keeps state while reading xml tokens. one parser per call so parsing is safe from goroutines
+type saxParser struct {
+	dec       *xml.Decoder
+	stmt      QueryStatement
+	eleType   declareElementType
+	id        string
+	stmtList  []QueryStatement
+	fragments map[string]string
 }
 
-var (
-	currentStmt    QueryStatement
-	currentEleType declareElementType
-	currentId      string
-	stmtList       []QueryStatement
-)
+// parseWithSax returns statements declared in xml data without normalizing
+func parseWithSax(data []byte) ([]QueryStatement, error) {
+	p := &saxParser{
+		dec:       xml.NewDecoder(bytes.NewBuffer(data)),
+		stmtList:  make([]QueryStatement, 0),
+		fragments: make(map[string]string),
+	}
 
-func loadWithSax(manager *StringMan, data []byte) error {
-	stmtList = make([]QueryStatement, 0)
-	buf := bytes.NewBuffer(data)
-	dec := xml.NewDecoder(buf)
+	err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return resolveIncludes(p.stmtList, p.fragments)
+}
 
+func (p *saxParser) parse() error {
 	for {
-		t, tokenErr := dec.Token()
+		t, tokenErr := p.dec.Token()
 		if tokenErr != nil {
 			if tokenErr == io.EOF {
-				break
+				return nil
 			}
 			return tokenErr
 		}
 
 		switch t := t.(type) {
 		case xml.StartElement:
-			currentId = getAttr(t.Attr, attrId)
-			currentEleType = buildElementType(t.Name.Local)
-			if currentEleType.IsText() {
-				currentStmt = newQueryStatement()
-				traverseIf(dec)
+			p.id = getAttr(t.Attr, attrId)
+			p.eleType = buildElementType(t.Name.Local)
+			if p.eleType.IsText() {
+				p.stmt = newQueryStatement(p.id)
+				p.stmt.engine = getAttr(t.Attr, attrEngine)
+				p.stmt.lang = normalizeLocale(getAttr(t.Attr, attrLang))
+				p.stmt.override = getAttr(t.Attr, attrOverride) == "true"
+				err := p.traverseIf()
+				if err != nil {
+					return err
+				}
+			}
+			if p.eleType == eleTypeFragment {
+				err := p.traverseFragment(p.id)
+				if err != nil {
+					return err
+				}
+				p.id = ""
 			}
 		case xml.CharData:
-			if len(currentId) == 0 {
+			if len(p.id) == 0 {
 				break
 			}
-			currentStmt.Query = currentStmt.Query + string(t)
+			p.stmt.Query = p.stmt.Query + string(t)
 		case xml.EndElement:
-			if currentEleType.IsText() {
-				currentStmt.Query = strings.Trim(currentStmt.Query, cutset)
-				currentId = ""
+			if p.eleType.IsText() {
+				p.stmt.Query = strings.Trim(p.stmt.Query, cutset)
+				p.id = ""
 			}
 		}
 	}
-
-	for _, v := range stmtList {
-		err := manager.registStatement(v)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
 }
 
-func traverseIf(dec *xml.Decoder) {
+func (p *saxParser) traverseIf() error {
 	//var innerElement declareElementType
 	//var innerSql = ""
 	//var innerKey = ""
 	//var innerExist = "true"
 
 	for {
-		t, tokenErr := dec.Token()
+		t, tokenErr := p.dec.Token()
 		if tokenErr != nil {
-			if tokenErr == io.EOF {
-				break
-			}
-			panic(tokenErr)
+			return unexpectedEOF(tokenErr)
 		}
 
 		switch t := t.(type) {
 		case xml.StartElement:
 			//innerKey = getAttr(t.Attr, attrKey)
 			//innerExist = getAttr(t.Attr, attrExist)
+			switch buildElementType(t.Name.Local) {
+			case eleTypeAllow:
+				err := p.traverseAllow(getAttr(t.Attr, attrParam))
+				if err != nil {
+					return err
+				}
+			case eleTypeInclude:
+				p.stmt.Query = p.stmt.Query + includeMarker(getAttr(t.Attr, attrRef))
+				err := p.dec.Skip()
+				if err != nil {
+					return unexpectedEOF(err)
+				}
+			}
+		case xml.CharData:
+			p.stmt.Query = p.stmt.Query + string(t)
+		case xml.EndElement:
+			if p.eleType.IsText() {
+				p.stmt.Query = strings.Trim(p.stmt.Query, cutset)
+				p.stmtList = append(p.stmtList, p.stmt)
+				return nil
+			}
+			p.id = ""
+		}
+	}
+}
+
+// traverseAllow reads allowed values for raw substitution.
+// e.g) <allow param="Sort">name, create_time</allow>
+func (p *saxParser) traverseAllow(param string) error {
+	var values string
+	for {
+		t, tokenErr := p.dec.Token()
+		if tokenErr != nil {
+			return unexpectedEOF(tokenErr)
+		}
+
+		switch t := t.(type) {
+		case xml.CharData:
+			values = values + string(t)
+		case xml.EndElement:
+			for _, v := range strings.Split(values, allowSeparator) {
+				v = strings.Trim(v, cutset)
+				if len(v) > 0 {
+					p.stmt.allowList[param] = append(p.stmt.allowList[param], v)
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// traverseFragment reads text shared by statements in same file.
+// e.g) <fragment id="cityColumns">id, name, population</fragment>
+func (p *saxParser) traverseFragment(id string) error {
+	var text string
+	for {
+		t, tokenErr := p.dec.Token()
+		if tokenErr != nil {
+			return unexpectedEOF(tokenErr)
+		}
+
+		switch t := t.(type) {
 		case xml.CharData:
-			currentStmt.Query = currentStmt.Query + string(t)
+			text = text + string(t)
+		case xml.StartElement:
+			return fmt.Errorf("unsupported element %s in fragment %s", t.Name.Local, id)
 		case xml.EndElement:
-			if currentEleType.IsText() {
-				currentStmt.Query = strings.Trim(currentStmt.Query, cutset)
-				stmtList = append(stmtList, currentStmt)
-				return
+			p.fragments[id] = strings.Trim(text, cutset)
+			return nil
+		}
+	}
+}
+
+// unexpectedEOF converts io.EOF inside element. element is not closed yet
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// includeMarker marks position of <include ref="..."/> until fragments of file are read.
+// NUL is not allowed in xml text so it never collides with statement text
+func includeMarker(ref string) string {
+	return "\x00" + ref + "\x00"
+}
+
+// resolveIncludes replaces include markers with fragment text declared in same file
+func resolveIncludes(list []QueryStatement, fragments map[string]string) ([]QueryStatement, error) {
+	for i, stmt := range list {
+		for strings.Contains(stmt.Query, "\x00") {
+			start := strings.Index(stmt.Query, "\x00")
+			end := strings.Index(stmt.Query[start+1:], "\x00") + start + 1
+			ref := stmt.Query[start+1 : end]
+			text, ok := fragments[ref]
+			if !ok {
+				return nil, fmt.Errorf("unknown fragment [%s] included in %s", ref, stmt.Id)
 			}
-			currentId = ""
+			stmt.Query = stmt.Query[:start] + text + stmt.Query[end+1:]
 		}
+		list[i] = stmt
 	}
+	return list, nil
 }
 
 func getAttr(attr []xml.Attr, name string) string {
@@ -220,16 +387,24 @@ func getAttr(attr []xml.Attr, name string) string {
 	return ""
 }
 
-func newQueryStatement() QueryStatement {
+func newQueryStatement(id string) QueryStatement {
 	stmt := QueryStatement{}
-	stmt.Id = currentId
+	stmt.Id = id
 	stmt.columnMention = make([]ColumnBind, 0)
+	stmt.allowList = make(map[string][]string)
 	return stmt
 }
 
 const (
-	attrId    = "id"
-	attrKey   = "key"
-	attrExist = "exist"
-	cutset    = "\r\t\n "
+	attrId       = "id"
+	attrKey      = "key"
+	attrExist    = "exist"
+	attrParam    = "param"
+	attrEngine   = "engine"
+	attrLang     = "lang"
+	attrOverride = "override"
+	attrRef      = "ref"
+	cutset       = "\r\t\n "
+
+	allowSeparator = ","
 )
@@ -23,11 +23,10 @@
 package stringman
 
 import (
-	"bytes"
-	"encoding/xml"
-	"io"
-	"strings"
+	"sync"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 var testXml = []byte(`
@@ -696,42 +695,72 @@ update tb_chnl c , (
 func TestLoaderComplicated(t *testing.T) {
 	queryNormalizer = newNormalizer()
 
-	stmtList = make([]QueryStatement, 0)
-	buf := bytes.NewBuffer(testXml)
-	dec := xml.NewDecoder(buf)
+	stmtList, err := parseWithSax(testXml)
+	if !assert.Nil(t, err) {
+		return
+	}
 
-	for {
-		t, tokenErr := dec.Token()
-		if tokenErr != nil {
-			if tokenErr == io.EOF {
-				break
-			}
-			panic(tokenErr)
-		}
+	if len(stmtList) != 43 {
+		t.Errorf("expect stmt len 43. %d", len(stmtList))
+	}
 
-		switch t := t.(type) {
-		case xml.StartElement:
-			currentId = getAttr(t.Attr, attrId)
-			currentEleType = buildElementType(t.Name.Local)
-			if currentEleType.IsText() {
-				currentStmt = newQueryStatement()
-				traverseIf(dec)
-			}
-		case xml.CharData:
-			if len(currentId) == 0 {
-				break
-			}
-			currentStmt.Query = currentStmt.Query + string(t)
-		case xml.EndElement:
-			if currentEleType.IsText() {
-				currentStmt.Query = strings.Trim(currentStmt.Query, cutset)
-				currentId = ""
+}
+
+func TestParseStatementsConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				list, err := ParseStatements("string.xml", testXml)
+				if !assert.Nil(t, err) || !assert.Equal(t, 43, len(list)) {
+					return
+				}
+				assert.Nil(t, newStringMan(NewStringmanPreference("")).registStatement(list[0]))
 			}
-		}
+		}()
 	}
+	wg.Wait()
+}
 
-	if len(stmtList) != 43 {
-		t.Errorf("expect stmt len 43. %d", len(stmtList))
+var fragmentXml = []byte(`
+<query>
+	<text id="SelectCity">
+		SELECT <include ref="cityColumns"/> FROM CITY WHERE NAME={Name}
+	</text>
+	<fragment id="cityColumns">
+		id, name, population
+	</fragment>
+</query>
+`)
+
+func TestParseFragment(t *testing.T) {
+	list, err := parseWithSax(fragmentXml)
+	if !assert.Nil(t, err) {
+		return
+	}
+	if assert.Equal(t, 1, len(list)) {
+		assert.Equal(t, "SELECT id, name, population FROM CITY WHERE NAME={Name}", list[0].Query)
 	}
 
+	_, err = parseWithSax([]byte(`<query><text id="A">SELECT <include ref="none"/> FROM CITY</text></query>`))
+	assert.NotNil(t, err)
+
+	_, err = parseWithSax([]byte(`<query><fragment id="cols">id, <include ref="x"/></fragment></query>`))
+	assert.NotNil(t, err)
+}
+
+func TestParseTruncatedXml(t *testing.T) {
+	truncated := []string{
+		`<query><text id="A">ab`,
+		`<query><text id="A"><allow param="Sort">name`,
+		`<query><text id="A">SELECT <include ref="x"`,
+		`<query><fragment id="cols">id`,
+	}
+
+	for _, data := range truncated {
+		_, err := parseWithSax([]byte(data))
+		assert.NotNil(t, err, data)
+	}
 }
@@ -0,0 +1,212 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type localeContextKey struct{}
+
+// WithLocale returns context carrying locale for FormatLocale lookups
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// LocaleFromContext returns locale stored by WithLocale. empty if not exists
+func LocaleFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeContextKey{}).(string); ok {
+		return locale
+	}
+	return ""
+}
+
+// LocaleFallbackRule lists locales searched before parent locale of Locale. e.g) zh-HK => [zh-TW]
+type LocaleFallbackRule struct {
+	Locale   string
+	Fallback []string
+}
+
+// fileLocale returns locale of file name like string.en.xml when the locale is one of locales.
+// empty for default file and dotted name which is not a listed locale. e.g) string.db.xml
+func fileLocale(file string, locales []string) string {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	idx := strings.LastIndexByte(name, '.')
+	if idx < 0 {
+		return ""
+	}
+
+	locale := normalizeLocale(name[idx+1:])
+	for _, l := range locales {
+		if normalizeLocale(l) == locale {
+			return locale
+		}
+	}
+	return ""
+}
+
+// normalizeLocale makes locale comparable. e.g) ko_KR => ko-kr
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// statementLocale returns locale of statement. DefaultLocale for statement without locale
+func (man *StringMan) statementLocale(stmt QueryStatement) string {
+	if len(stmt.lang) > 0 {
+		return stmt.lang
+	}
+	return man.preference.DefaultLocale
+}
+
+// localeChain returns locales to search in order. explicit fallback is searched before parent locale
+// and default statements are searched after the chain
+// e.g) ko-KR => [ko-kr, ko]
+func (man *StringMan) localeChain(locale string) []string {
+	chain := make([]string, 0)
+	appendLocale := func(l string) {
+		for _, v := range chain {
+			if v == l {
+				return
+			}
+		}
+		chain = append(chain, l)
+	}
+
+	var walk func(l string)
+	walk = func(l string) {
+		for len(l) > 0 {
+			appendLocale(l)
+			for _, rule := range man.preference.LocaleFallback {
+				if normalizeLocale(rule.Locale) != l {
+					continue
+				}
+				for _, f := range rule.Fallback {
+					if f := normalizeLocale(f); !containsString(chain, f) {
+						walk(f)
+					}
+				}
+			}
+
+			idx := strings.LastIndexByte(l, '-')
+			if idx < 0 {
+				break
+			}
+			l = l[:idx]
+		}
+	}
+
+	walk(normalizeLocale(locale))
+	return chain
+}
+
+func (man *StringMan) findLocale(locale string, id string) (QueryStatement, error) {
+	key := strings.ToUpper(id)
+	statements := man.statements()
+	for _, l := range man.localeChain(locale) {
+		if stmt, ok := statements.localeMap[l][key]; ok {
+			return stmt, nil
+		}
+	}
+
+	stmt, ok := statements.statementMap[key]
+	if !ok {
+		return stmt, fmt.Errorf("not found text statement for id : %s (locale=%s)", id, locale)
+	}
+	return stmt, nil
+}
+
+// FormatLocale works like FormatWithStmt with statement found by locale fallback chain.
+// e.g) ko-KR => ko => default
+func (man *StringMan) FormatLocale(locale string, stmtId string, param ...interface{}) (string, error) {
+	stmt, err := man.findLocale(locale, stmtId)
+	if err != nil {
+		return "", err
+	}
+
+	return man.formatStatement(stmt, param...)
+}
+
+// FormatNamedLocale works like FormatNamed with statement found by locale fallback chain
+func (man *StringMan) FormatNamedLocale(locale string, stmtId string, param map[string]interface{}) (string, error) {
+	stmt, err := man.findLocale(locale, stmtId)
+	if err != nil {
+		return "", err
+	}
+
+	if stmt.IsTemplate() {
+		return "", fmt.Errorf("%s is template statement. use Render", stmtId)
+	}
+
+	params := man.newParamIndex(param)
+	err = man.checkUnusedParams(stmt, params)
+	if err != nil {
+		return "", err
+	}
+
+	// plural rule follows language of found statement. requested locale may fall back to another language
+	return man.completeText(stmt, params, renderText, man.statementLocale(stmt))
+}
+
+// Locales returns loaded locales in order
+func (man *StringMan) Locales() []string {
+	localeMap := man.statements().localeMap
+	locales := make([]string, 0, len(localeMap))
+	for k := range localeMap {
+		locales = append(locales, k)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// MissingLocaleIds reports ids translated in other locales but missing in each locale.
+// statements declared only without locale (e.g. sql) and templates are not reported
+func (man *StringMan) MissingLocaleIds() map[string][]string {
+	statements := man.statements()
+	all := make(map[string]string)
+	for _, catalog := range statements.localeMap {
+		for k, v := range catalog {
+			if !v.IsTemplate() {
+				all[k] = v.Id
+			}
+		}
+	}
+
+	report := make(map[string][]string)
+	for locale, catalog := range statements.localeMap {
+		missing := make([]string, 0)
+		for k, id := range all {
+			if _, ok := catalog[k]; !ok {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			report[locale] = missing
+		}
+	}
+	return report
+}
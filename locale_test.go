@@ -0,0 +1,166 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var localeXmlDefault = []byte(`
+<query>
+	<text id="Greeting">
+		hello %s
+	</text>
+	<text id="Farewell">
+		bye {Name}
+	</text>
+	<text id="Greeting" lang="ko-KR">
+		%s님, 반갑습니다
+	</text>
+	<text id="SelectCity">
+		SELECT * FROM CITY WHERE NAME={Name}
+	</text>
+</query>
+`)
+
+var localeXmlKo = []byte(`
+<query>
+	<text id="Greeting">
+		%s님, 안녕하세요
+	</text>
+	<text id="Farewell">
+		{Name}님, 안녕히 가세요
+	</text>
+</query>
+`)
+
+var localeXmlJa = []byte(`
+<query>
+	<text id="Greeting">
+		%sさん、こんにちは
+	</text>
+</query>
+`)
+
+func TestFileLocale(t *testing.T) {
+	locales := []string{"en", "ko-KR"}
+	assert.Equal(t, "en", fileLocale("/tmp/string.en.xml", locales))
+	assert.Equal(t, "ko-kr", fileLocale("string.ko_KR.xml", locales))
+	assert.Equal(t, "", fileLocale("string.xml", locales))
+	assert.Equal(t, "", fileLocale("string.gen.xml", locales))
+	assert.Equal(t, "", fileLocale("string.db.xml", locales))
+	assert.Equal(t, "", fileLocale("string.en.xml", nil))
+}
+
+var dottedXml = []byte(`
+<query>
+	<text id="SelectUser">
+		SELECT * FROM USER WHERE ID={Id}
+	</text>
+</query>
+`)
+
+// dotted file name which is not a listed locale keeps default statements
+func TestDottedFileName(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-dotted")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.db.xml"), dottedXml, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.en.xml"), localeXmlDefault, 0644))
+
+	pref := NewStringmanPreference(dir)
+	pref.FileLocales = []string{"en"}
+	man, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	built, err := man.BuildWithStmt("SelectUser", BuildParam{"Id": 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM USER WHERE ID=1", built)
+	assert.Equal(t, []string{"en", "ko-kr"}, man.Locales())
+	_, err = man.BuildWithStmt("SelectCity", BuildParam{"Name": "seoul"})
+	assert.NotNil(t, err)
+}
+
+func TestFormatLocale(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-locale")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.xml"), localeXmlDefault, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.ko.xml"), localeXmlKo, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.ja.xml"), localeXmlJa, 0644))
+
+	pref := NewStringmanPreference(dir)
+	pref.FileLocales = []string{"ko", "ja"}
+	pref.LocaleFallback = []LocaleFallbackRule{{Locale: "ja-KR", Fallback: []string{"ko"}}}
+	man, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	built, err := man.FormatLocale("ko-KR", "greeting", "홍길동")
+	assert.Nil(t, err)
+	assert.Equal(t, "홍길동님, 반갑습니다", built)
+
+	built, err = man.FormatLocale("ko", "greeting", "홍길동")
+	assert.Nil(t, err)
+	assert.Equal(t, "홍길동님, 안녕하세요", built)
+
+	built, err = man.FormatLocale("en-US", "greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello fatima", built)
+
+	ctx := WithLocale(context.Background(), "ko_KR")
+	built, err = man.FormatNamedLocale(LocaleFromContext(ctx), "farewell", map[string]interface{}{"Name": "홍길동"})
+	assert.Nil(t, err)
+	assert.Equal(t, "홍길동님, 안녕히 가세요", built)
+
+	assert.Equal(t, []string{"ja-kr", "ko", "ja"}, man.localeChain("ja-KR"))
+	man.preference.LocaleFallback = append(man.preference.LocaleFallback, LocaleFallbackRule{Locale: "ja_kr", Fallback: []string{"en"}})
+	assert.Equal(t, []string{"ja-kr", "ko", "en", "ja"}, man.localeChain("ja-KR"))
+	man.preference.LocaleFallback = pref.LocaleFallback
+	built, err = man.FormatNamedLocale("ja-KR", "farewell", map[string]interface{}{"Name": "fatima"})
+	assert.Nil(t, err)
+	assert.Equal(t, "fatima님, 안녕히 가세요", built)
+
+	assert.Equal(t, []string{"ja", "ko", "ko-kr"}, man.Locales())
+	missing := man.MissingLocaleIds()
+	assert.Equal(t, []string{"Farewell"}, missing["ja"])
+	assert.Equal(t, []string{"Farewell"}, missing["ko-kr"])
+	assert.NotContains(t, missing, "ko")
+	for _, ids := range missing {
+		assert.NotContains(t, ids, "SelectCity")
+	}
+}
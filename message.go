@@ -0,0 +1,304 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	choiceKindPlural = "plural"
+	choiceKindSelect = "select"
+	choiceOther      = "other"
+	choiceOffset     = "offset:"
+	choiceExactMark  = "="
+	pluralNumberMark = "#"
+)
+
+// messageChoice is ICU MessageFormat style plural/select argument.
+// e.g) {Count, plural, =0{no ticket} one{# ticket} other{# tickets}}
+type messageChoice struct {
+	kind   string
+	offset float64
+	cases  []choiceCase
+}
+
+type choiceCase struct {
+	key  string
+	stmt QueryStatement
+}
+
+var choiceHeadRegex = regexp.MustCompile(`^\s*([A-Za-z0-9_.\[\]]+)\s*,\s*(plural|select)\s*,`)
+
+// matchChoice returns index of closing delimiter when plural/select argument starts at start
+func matchChoice(query string, start int) (int, bool) {
+	if !choiceHeadRegex.MatchString(query[start+1:]) {
+		return 0, false
+	}
+	end := matchDelimiter(query, start)
+	return end, end > start
+}
+
+// matchDelimiter returns index of delimiter closing the one at start. -1 if not closed
+func matchDelimiter(query string, start int) int {
+	depth := 0
+	for i := start; i < len(query); i++ {
+		switch query[i] {
+		case delimStartCharacter:
+			depth++
+		case delimStopString[0]:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseChoiceBind parses declaration inside delimiter like "Count, plural, one{# ticket} other{# tickets}"
+func parseChoiceBind(declare string, pos int) (ColumnBind, error) {
+	head := choiceHeadRegex.FindStringSubmatch(declare)
+	b := NewColumnBind(head[1], pos)
+	b.bindType = columnBindTypeChoice
+	path, err := parseParamPath(b.name)
+	if err != nil {
+		return b, err
+	}
+	b.path = path
+	choice := &messageChoice{kind: head[2]}
+
+	rest := declare[len(head[0]):]
+	for {
+		rest = strings.TrimLeft(rest, cutset)
+		if len(rest) == 0 {
+			break
+		}
+
+		openIndex := strings.IndexByte(rest, delimStartCharacter)
+		if openIndex < 0 {
+			return b, fmt.Errorf("invalid %s declare for %s", choice.kind, b.name)
+		}
+
+		key := strings.TrimSpace(rest[:openIndex])
+		if strings.HasPrefix(key, choiceOffset) && choice.kind == choiceKindPlural {
+			fields := strings.Fields(key)
+			offset, err := strconv.ParseFloat(strings.TrimPrefix(fields[0], choiceOffset), 64)
+			if err != nil {
+				return b, fmt.Errorf("invalid plural offset for %s", b.name)
+			}
+			choice.offset = offset
+			key = strings.TrimSpace(strings.TrimPrefix(key, fields[0]))
+		}
+		if len(key) == 0 || strings.ContainsAny(key, cutset) {
+			return b, fmt.Errorf("invalid %s selector [%s] for %s", choice.kind, key, b.name)
+		}
+
+		closeIndex := matchDelimiter(rest, openIndex)
+		if closeIndex < 0 {
+			return b, fmt.Errorf("incompleted %s message for %s", choice.kind, b.name)
+		}
+
+		body := rest[openIndex+1 : closeIndex]
+		columns, holded, err := holdVariables(body)
+		if err != nil {
+			return b, err
+		}
+		stmt := QueryStatement{Id: b.name + "." + key, Query: body, HoldedQuery: holded, columnMention: columns}
+		choice.cases = append(choice.cases, choiceCase{key: key, stmt: stmt})
+		rest = rest[closeIndex+1:]
+	}
+
+	if _, ok := choice.find(choiceOther); !ok {
+		return b, fmt.Errorf("%s for %s needs other message", choice.kind, b.name)
+	}
+
+	b.choice = choice
+	return b, nil
+}
+
+func (m *messageChoice) find(key string) (choiceCase, bool) {
+	for _, c := range m.cases {
+		if c.key == key {
+			return c, true
+		}
+	}
+	return choiceCase{}, false
+}
+
+// selectCase returns message for value. plural checks exact match(=N) first, then plural category of locale
+func (m *messageChoice) selectCase(value interface{}, locale string) (choiceCase, string, error) {
+	if m.kind == choiceKindSelect {
+		key := fmt.Sprint(value)
+		if c, ok := m.find(key); ok {
+			return c, "", nil
+		}
+		c, _ := m.find(choiceOther)
+		return c, "", nil
+	}
+
+	n, err := toFloat(value)
+	if err != nil {
+		return choiceCase{}, "", err
+	}
+
+	for _, c := range m.cases {
+		if !strings.HasPrefix(c.key, choiceExactMark) {
+			continue
+		}
+		exact, err := strconv.ParseFloat(strings.TrimPrefix(c.key, choiceExactMark), 64)
+		if err == nil && exact == n {
+			return c, formatPluralNumber(n - m.offset), nil
+		}
+	}
+
+	number := formatPluralNumber(n - m.offset)
+	category := pluralCategory(locale, newPluralOperands(number))
+	if c, ok := m.find(category); ok {
+		return c, number, nil
+	}
+	c, _ := m.find(choiceOther)
+	return c, number, nil
+}
+
+func toFloat(value interface{}) (float64, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return strconv.ParseFloat(v.String(), 64)
+	}
+	return 0, fmt.Errorf("plural needs number but %v", reflect.TypeOf(value))
+}
+
+func formatPluralNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// PluralOperands is CLDR plural operands of number
+// N: absolute value, I: integer digits, V: number of visible fraction digits, F: visible fraction digits
+type PluralOperands struct {
+	N float64
+	I int64
+	V int
+	F int64
+}
+
+func newPluralOperands(number string) PluralOperands {
+	op := PluralOperands{}
+	number = strings.TrimPrefix(number, "-")
+	op.N, _ = strconv.ParseFloat(number, 64)
+	integer := number
+	if idx := strings.IndexByte(number, '.'); idx >= 0 {
+		integer = number[:idx]
+		fraction := number[idx+1:]
+		op.V = len(fraction)
+		op.F, _ = strconv.ParseInt(fraction, 10, 64)
+	}
+	op.I, _ = strconv.ParseInt(integer, 10, 64)
+	return op
+}
+
+// PluralRule returns CLDR plural category (zero, one, two, few, many, other) of number
+type PluralRule func(op PluralOperands) string
+
+var (
+	pluralMutex sync.RWMutex
+	pluralRules = map[string]PluralRule{
+		"ko": pluralRuleOther,
+		"ja": pluralRuleOther,
+		"zh": pluralRuleOther,
+		"en": pluralRuleEnglish,
+	}
+)
+
+// RegisterPluralRule adds (or replaces) cardinal plural rule of language. e.g) fr, ru
+func RegisterPluralRule(lang string, rule PluralRule) {
+	pluralMutex.Lock()
+	defer pluralMutex.Unlock()
+	pluralRules[normalizeLocale(lang)] = rule
+}
+
+// pluralCategory finds rule with locale and its parent. e.g) en-US => en. other without rule
+func pluralCategory(locale string, op PluralOperands) string {
+	pluralMutex.RLock()
+	defer pluralMutex.RUnlock()
+
+	l := normalizeLocale(locale)
+	for len(l) > 0 {
+		if rule, ok := pluralRules[l]; ok {
+			return rule(op)
+		}
+		idx := strings.LastIndexByte(l, '-')
+		if idx < 0 {
+			break
+		}
+		l = l[:idx]
+	}
+	return choiceOther
+}
+
+// pluralRuleOther is for languages without plural form. ko, ja, zh
+func pluralRuleOther(op PluralOperands) string {
+	return choiceOther
+}
+
+// pluralRuleEnglish : one => i = 1 and v = 0
+func pluralRuleEnglish(op PluralOperands) string {
+	if op.I == 1 && op.V == 0 {
+		return "one"
+	}
+	return choiceOther
+}
+
+// completeChoice renders message selected by plural/select argument
+func (man *StringMan) completeChoice(c ColumnBind, params paramIndex, mode renderMode, locale string) (string, error) {
+	v, err := man.resolveParam(params, c)
+	if _, missing := err.(paramNotFound); err != nil && !missing {
+		return "", fmt.Errorf("param %s : %s", c.name, err.Error())
+	}
+	if err != nil {
+		return "", err
+	}
+
+	selected, number, err := c.choice.selectCase(v, locale)
+	if err != nil {
+		return "", fmt.Errorf("%s : %s", c.name, err.Error())
+	}
+
+	stmt := selected.stmt
+	if c.choice.kind == choiceKindPlural {
+		stmt.HoldedQuery = strings.ReplaceAll(stmt.HoldedQuery, pluralNumberMark, number)
+	}
+	return man.completeText(stmt, params, mode, locale)
+}
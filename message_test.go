@@ -0,0 +1,115 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChoiceBind(t *testing.T) {
+	columns, holded, err := holdVariables("총 {Count, plural, =0{없음} one{# ticket} other{# tickets for {Name}}}")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "총 \x00", holded)
+	assert.Equal(t, 1, len(columns))
+	assert.True(t, columns[0].IsChoice())
+	assert.Equal(t, "Count", columns[0].Name())
+	assert.Equal(t, 3, len(columns[0].choice.cases))
+
+	stmt := QueryStatement{columnMention: columns}
+	assert.Equal(t, 2, len(stmt.Columns()))
+
+	_, _, err = holdVariables("{Count, plural, one{# ticket}}")
+	assert.NotNil(t, err)
+
+	_, _, err = holdVariables("{Count, plural, one{# ticket} other{# tickets}")
+	assert.NotNil(t, err)
+}
+
+func TestPluralCategory(t *testing.T) {
+	assert.Equal(t, "one", pluralCategory("en-US", newPluralOperands("1")))
+	assert.Equal(t, "other", pluralCategory("en", newPluralOperands("1.0")))
+	assert.Equal(t, "other", pluralCategory("en", newPluralOperands("2")))
+	assert.Equal(t, "other", pluralCategory("ko", newPluralOperands("1")))
+	assert.Equal(t, "other", pluralCategory("ja", newPluralOperands("1")))
+	assert.Equal(t, "other", pluralCategory("zh-TW", newPluralOperands("1")))
+	assert.Equal(t, "other", pluralCategory("", newPluralOperands("1")))
+
+	op := newPluralOperands("-12.50")
+	assert.Equal(t, int64(12), op.I)
+	assert.Equal(t, 2, op.V)
+	assert.Equal(t, int64(50), op.F)
+}
+
+func TestFormatNamedPluralAndSelect(t *testing.T) {
+	man := newStringMan(NewStringmanPreference(""))
+	err := man.registStatement(QueryStatement{Id: "TicketCount", lang: "en",
+		Query: "{Gender, select, female{She} male{He} other{They}} bought {Count, plural, =0{no tickets} one{# ticket} other{# tickets}}"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	err = man.registStatement(QueryStatement{Id: "TicketCount", lang: "ko", Query: "{Name}님 (총 {Count, plural, other{#매}})"})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	cases := []struct {
+		locale string
+		param  map[string]interface{}
+		expect string
+	}{
+		{"en-US", map[string]interface{}{"Gender": "female", "Count": 1}, "She bought 1 ticket"},
+		{"en", map[string]interface{}{"Gender": "male", "Count": 0}, "He bought no tickets"},
+		{"en", map[string]interface{}{"Gender": "unknown", "Count": 3}, "They bought 3 tickets"},
+		{"ko-KR", map[string]interface{}{"Name": "홍길동", "Count": 1}, "홍길동님 (총 1매)"},
+	}
+
+	for _, c := range cases {
+		built, err := man.FormatNamedLocale(c.locale, "ticketCount", c.param)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		assert.Equal(t, c.expect, built)
+	}
+
+	_, err = man.FormatNamedLocale("en", "ticketCount", map[string]interface{}{"Gender": "male"})
+	assert.NotNil(t, err)
+}
+
+func TestFormatNamedLocaleFallbackPlural(t *testing.T) {
+	pref := NewStringmanPreference("")
+	pref.DefaultLocale = "en"
+	man := newStringMan(pref)
+	err := man.registStatement(QueryStatement{Id: "Tickets", Query: "{Count, plural, one{# ticket} other{# tickets}}"})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	built, err := man.FormatNamedLocale("ko", "Tickets", map[string]interface{}{"Count": 1})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "1 ticket", built)
+	}
+}
@@ -0,0 +1,409 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// UnusedParamIgnore ignores parameter which is not declared in statement
+	UnusedParamIgnore UnusedParamMode = iota
+	// UnusedParamWarn writes unused parameter names to DebugLogger
+	UnusedParamWarn
+	// UnusedParamStrict fails completing text with unused parameter
+	UnusedParamStrict
+)
+
+const (
+	// ParamMatchExact binds parameter whose name is same as variable
+	ParamMatchExact ParamMatchMode = iota
+	// ParamMatchIgnoreCase binds parameter whose name is same as variable ignoring case. e.g) {id} binds ID
+	ParamMatchIgnoreCase
+	// ParamMatchConvert binds parameter whose name is same as variable after converting both with
+	// FieldNameConvertStrategy. e.g) {member_type} binds MemberType
+	ParamMatchConvert
+)
+
+type ParamMatchMode uint8
+
+func (p ParamMatchMode) String() string {
+	switch p {
+	case ParamMatchExact:
+		return "EXACT"
+	case ParamMatchIgnoreCase:
+		return "IGNORE_CASE"
+	case ParamMatchConvert:
+		return "CONVERT"
+	}
+	return "UNKNOWN"
+}
+
+type UnusedParamMode uint8
+
+func (u UnusedParamMode) String() string {
+	switch u {
+	case UnusedParamIgnore:
+		return "IGNORE"
+	case UnusedParamWarn:
+		return "WARN"
+	case UnusedParamStrict:
+		return "STRICT"
+	}
+	return "UNKNOWN"
+}
+
+// MatchParamName reports whether parameter key binds variable name by StringmanPreference.ParamMatchMode
+func (man *StringMan) MatchParamName(name string, key string) bool {
+	if name == key {
+		return true
+	}
+
+	switch man.preference.ParamMatchMode {
+	case ParamMatchIgnoreCase:
+		return strings.EqualFold(name, key)
+	case ParamMatchConvert:
+		return man.fieldNameConverter.convertFieldName(name) == man.fieldNameConverter.convertFieldName(key)
+	}
+	return false
+}
+
+// paramIndex finds parameter keys bound to variable name. it is built once for completing a text
+type paramIndex struct {
+	param      BuildParam
+	normalized map[string][]string // normalized name => sorted keys. nil for ParamMatchExact
+	normalize  func(string) string
+}
+
+func (man *StringMan) newParamIndex(param BuildParam) paramIndex {
+	idx := paramIndex{param: param}
+	switch man.preference.ParamMatchMode {
+	case ParamMatchIgnoreCase:
+		idx.normalize = strings.ToLower
+	case ParamMatchConvert:
+		idx.normalize = man.fieldNameConverter.convertFieldName
+	default:
+		return idx
+	}
+
+	idx.normalized = make(map[string][]string)
+	for k := range param {
+		n := idx.normalize(k)
+		idx.normalized[n] = append(idx.normalized[n], k)
+	}
+	for _, keys := range idx.normalized {
+		sort.Strings(keys)
+	}
+	return idx
+}
+
+// keys returns parameter keys bound to name. exactly same name is preferred
+func (idx paramIndex) keys(name string) []string {
+	if _, ok := idx.param[name]; ok {
+		return []string{name}
+	}
+	if idx.normalized == nil {
+		return nil
+	}
+	return idx.normalized[idx.normalize(name)]
+}
+
+// lookupParam returns value of parameter bound to variable name.
+// fails when several keys are bound to name without exactly same one. e.g) {Id} with id and ID
+func (idx paramIndex) lookupParam(name string) (interface{}, bool, error) {
+	keys := idx.keys(name)
+	switch len(keys) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return idx.param[keys[0]], true, nil
+	}
+	return nil, false, fmt.Errorf("ambiguous params %s for %s", strings.Join(keys, ", "), name)
+}
+
+// unusedParams returns sorted keys of param not bound to any variable of statement.
+// variables of every plural/select case are regarded as declared
+func (idx paramIndex) unusedParams(stmt QueryStatement) []string {
+	used := make(map[string]bool)
+	for _, c := range stmt.Columns() {
+		keys := idx.keys(c.name)
+		if len(keys) == 0 && len(c.path) > 0 {
+			keys = idx.keys(c.RootName())
+		}
+		for _, k := range keys {
+			used[k] = true
+		}
+	}
+
+	unused := make([]string, 0)
+	for k := range idx.param {
+		if !used[k] {
+			unused = append(unused, k)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// checkUnusedParams reports unused parameters by StringmanPreference.UnusedParamMode
+func (man *StringMan) checkUnusedParams(stmt QueryStatement, params paramIndex) error {
+	mode := man.preference.UnusedParamMode
+	if mode == UnusedParamIgnore || len(params.param) == 0 {
+		return nil
+	}
+
+	unused := params.unusedParams(stmt)
+	if len(unused) == 0 {
+		return nil
+	}
+
+	switch mode {
+	case UnusedParamWarn:
+		man.preference.DebugLogger.Printf("unused params of %s : %s", stmt.Id, strings.Join(unused, ", "))
+	case UnusedParamStrict:
+		return fmt.Errorf("unused params of %s : %s", stmt.Id, strings.Join(unused, ", "))
+	}
+	return nil
+}
+
+// paramSegment is a segment of nested parameter path. e.g) Items, [0]
+type paramSegment struct {
+	name  string
+	index int // -1 for field or map key
+}
+
+func (p paramSegment) String() string {
+	if p.index >= 0 {
+		return fmt.Sprintf("[%d]", p.index)
+	}
+	return p.name
+}
+
+// parseParamPath parses nested path like Order.Items[0].Sku. nil for plain name
+func parseParamPath(name string) ([]paramSegment, error) {
+	if !strings.ContainsAny(name, ".[") {
+		return nil, nil
+	}
+
+	path := make([]paramSegment, 0)
+	rest := name
+	for len(rest) > 0 {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid parameter path %s : not closed index", name)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid parameter path %s : invalid index %s", name, rest[1:end])
+			}
+			path = append(path, paramSegment{index: index})
+			rest = rest[end+1:]
+		case rest[0] == '.' && len(path) > 0:
+			rest = rest[1:]
+			fallthrough
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			field := rest[:end]
+			if !identifierRegex.MatchString(field) || strings.Contains(field, ".") {
+				return nil, fmt.Errorf("invalid parameter path %s : invalid segment %q", name, field)
+			}
+			path = append(path, paramSegment{name: field, index: -1})
+			rest = rest[end:]
+		}
+	}
+
+	if path[0].index >= 0 {
+		return nil, fmt.Errorf("invalid parameter path %s : starts with index", name)
+	}
+	return path, nil
+}
+
+// paramNotFound means that value of variable does not exist. default or optional value is used for it
+type paramNotFound struct {
+	name   string
+	reason string
+}
+
+func (e paramNotFound) Error() string {
+	if len(e.reason) > 0 {
+		return fmt.Sprintf("not found param %s : %s", e.name, e.reason)
+	}
+	return fmt.Sprintf("not found param %s", e.name)
+}
+
+// resolveParam returns value bound to variable. nested path is resolved by reflection
+// when there is no parameter with whole name of variable
+func (man *StringMan) resolveParam(params paramIndex, c ColumnBind) (interface{}, error) {
+	v, ok, err := params.lookupParam(c.name)
+	if err != nil || ok {
+		return v, err
+	}
+	if len(c.path) == 0 {
+		return nil, paramNotFound{name: c.name}
+	}
+
+	root, ok, err := params.lookupParam(c.path[0].name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, paramNotFound{name: c.name}
+	}
+	return man.walkParamPath(c, root)
+}
+
+func (man *StringMan) walkParamPath(c ColumnBind, root interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(root)
+	trail := c.path[0].name
+	for _, seg := range c.path[1:] {
+		for rv.IsValid() && (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) {
+			if rv.IsNil() {
+				break
+			}
+			rv = rv.Elem()
+		}
+		if !rv.IsValid() || isNilValue(rv) {
+			return nil, paramNotFound{name: c.name, reason: fmt.Sprintf("%s is nil", trail)}
+		}
+
+		var err error
+		rv, err = man.paramSegmentValue(rv, seg, trail)
+		if err != nil {
+			if notFound, ok := err.(paramNotFound); ok {
+				notFound.name = c.name
+				return nil, notFound
+			}
+			return nil, err
+		}
+		if seg.index >= 0 {
+			trail = trail + seg.String()
+		} else {
+			trail = trail + "." + seg.name
+		}
+	}
+
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	return rv.Interface(), nil
+}
+
+// paramSegmentValue returns field, map value or element of rv for segment. trail is path before segment
+func (man *StringMan) paramSegmentValue(rv reflect.Value, seg paramSegment, trail string) (reflect.Value, error) {
+	if seg.index >= 0 {
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			if seg.index >= rv.Len() {
+				return rv, fmt.Errorf("index %d out of range of %s (len=%d)", seg.index, trail, rv.Len())
+			}
+			return rv.Index(seg.index), nil
+		case reflect.Map:
+			if isIntKind(rv.Type().Key().Kind()) {
+				v := rv.MapIndex(reflect.ValueOf(seg.index).Convert(rv.Type().Key()))
+				if !v.IsValid() {
+					return rv, paramNotFound{reason: fmt.Sprintf("key %d not found in %s", seg.index, trail)}
+				}
+				return v, nil
+			}
+		}
+		return rv, fmt.Errorf("%s is not indexable (%s)", trail, rv.Type())
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		return man.structField(rv, seg.name, trail)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		v := rv.MapIndex(reflect.ValueOf(seg.name).Convert(rv.Type().Key()))
+		if v.IsValid() {
+			return v, nil
+		}
+		if man.preference.ParamMatchMode != ParamMatchExact {
+			// order of MapKeys is random. several matched keys are ambiguous as top level param
+			matched := make([]string, 0)
+			for _, k := range rv.MapKeys() {
+				if man.MatchParamName(seg.name, k.String()) {
+					matched = append(matched, k.String())
+				}
+			}
+			switch len(matched) {
+			case 0:
+			case 1:
+				return rv.MapIndex(reflect.ValueOf(matched[0]).Convert(rv.Type().Key())), nil
+			default:
+				sort.Strings(matched)
+				return rv, fmt.Errorf("ambiguous keys %s for %s in %s", strings.Join(matched, ", "), seg.name, trail)
+			}
+		}
+		return rv, paramNotFound{reason: fmt.Sprintf("key %s not found in %s", seg.name, trail)}
+	}
+	return rv, fmt.Errorf("%s is not struct or map (%s)", trail, rv.Type())
+}
+
+func (man *StringMan) structField(rv reflect.Value, name string, trail string) (reflect.Value, error) {
+	t := rv.Type()
+	field, ok := t.FieldByName(name)
+	if !ok && man.preference.ParamMatchMode != ParamMatchExact {
+		for i := 0; i < t.NumField(); i++ {
+			if man.MatchParamName(name, t.Field(i).Name) {
+				field, ok = t.Field(i), true
+				break
+			}
+		}
+	}
+	if !ok {
+		return rv, fmt.Errorf("field %s not found in %s (%s)", name, trail, t)
+	}
+	if len(field.PkgPath) > 0 {
+		return rv, fmt.Errorf("field %s of %s is not exported (%s)", name, trail, t)
+	}
+	for i, x := range field.Index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return rv, paramNotFound{reason: fmt.Sprintf("embedded field of %s is nil", trail)}
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, nil
+}
+
+func isNilValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
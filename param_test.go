@@ -0,0 +1,224 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type captureLogger struct {
+	lines []string
+}
+
+func (c *captureLogger) Printf(format string, a ...interface{}) {
+	c.lines = append(c.lines, fmt.Sprintf(format, a...))
+}
+
+func TestUnusedParams(t *testing.T) {
+	stmt := QueryStatement{Id: "UpdateScore", Query: "UPDATE MEMBER SET SCORE={Score=0} WHERE ID={Id}"}
+
+	man := newStringMan(NewStringmanPreference(""))
+	assert.Nil(t, man.registStatement(stmt))
+	built, err := man.BuildWithStmt("UpdateScore", BuildParam{"Id": 1, "Scroe": 10})
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE MEMBER SET SCORE=0 WHERE ID=1", built)
+
+	logger := &captureLogger{}
+	pref := NewStringmanPreference("")
+	pref.UnusedParamMode = UnusedParamWarn
+	pref.DebugLogger = logger
+	man = newStringMan(pref)
+	assert.Nil(t, man.registStatement(stmt))
+	_, err = man.BuildWithStmt("UpdateScore", BuildParam{"Id": 1, "Scroe": 10, "Name": "fatima"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"unused params of UpdateScore : Name, Scroe"}, logger.lines)
+
+	pref.UnusedParamMode = UnusedParamStrict
+	man = newStringMan(pref)
+	assert.Nil(t, man.registStatement(stmt))
+	_, err = man.BuildWithStmt("UpdateScore", BuildParam{"Id": 1, "Scroe": 10})
+	assert.EqualError(t, err, "unused params of UpdateScore : Scroe")
+
+	built, err = man.BuildWithStmt("UpdateScore", BuildParam{"Id": 1, "Score": 10})
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE MEMBER SET SCORE=10 WHERE ID=1", built)
+
+	assert.Nil(t, man.registStatement(QueryStatement{Id: "TicketCount", Query: "{Count, plural, one{# ticket for {Name}} other{# tickets}}"}))
+	_, err = man.FormatNamed("TicketCount", map[string]interface{}{"Count": 2, "Name": "fatima"})
+	assert.Nil(t, err)
+	_, err = man.FormatNamed("TicketCount", map[string]interface{}{"Count": 2, "Nmae": "fatima"})
+	assert.NotNil(t, err)
+}
+
+func TestParamMatchMode(t *testing.T) {
+	stmt := QueryStatement{Id: "SelectMember", Query: "SELECT * FROM MEMBER WHERE ID={Id} AND TYPE={member_type}"}
+
+	man := newStringMan(NewStringmanPreference(""))
+	assert.Nil(t, man.registStatement(stmt))
+	_, err := man.BuildWithStmt("SelectMember", BuildParam{"ID": 1, "MemberType": "A"})
+	assert.NotNil(t, err)
+
+	pref := NewStringmanPreference("")
+	pref.ParamMatchMode = ParamMatchIgnoreCase
+	man = newStringMan(pref)
+	assert.Nil(t, man.registStatement(stmt))
+	built, err := man.BuildWithStmt("SelectMember", BuildParam{"ID": 1, "MEMBER_TYPE": "A"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM MEMBER WHERE ID=1 AND TYPE='A'", built)
+
+	// exactly same name is preferred
+	built, err = man.BuildWithStmt("SelectMember", BuildParam{"ID": 1, "Id": 2, "member_type": "A"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM MEMBER WHERE ID=2 AND TYPE='A'", built)
+
+	// several keys bound without exactly same one
+	_, err = man.BuildWithStmt("SelectMember", BuildParam{"ID": 1, "id": 2, "member_type": "A"})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "ambiguous params ID, id for Id")
+	}
+
+	// same check for nested map keys
+	nested := QueryStatement{Id: "SelectUser", Query: "SELECT * FROM USER WHERE ID={User.id}"}
+	assert.Nil(t, man.registStatement(nested))
+	built, err = man.BuildWithStmt("SelectUser", BuildParam{"User": map[string]interface{}{"ID": 1}})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM USER WHERE ID=1", built)
+	built, err = man.BuildWithStmt("SelectUser", BuildParam{"User": map[string]interface{}{"ID": 1, "id": 2}})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM USER WHERE ID=2", built)
+	_, err = man.BuildWithStmt("SelectUser", BuildParam{"User": map[string]interface{}{"ID": 1, "Id": 2}})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "ambiguous keys ID, Id for id")
+	}
+
+	// key hidden by exactly same name is not used
+	pref.UnusedParamMode = UnusedParamStrict
+	strict := newStringMan(pref)
+	assert.Nil(t, strict.registStatement(stmt))
+	_, err = strict.BuildWithStmt("SelectMember", BuildParam{"ID": 1, "Id": 2, "member_type": "A"})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "unused params of SelectMember : ID")
+	}
+
+	pref.ParamMatchMode = ParamMatchConvert
+	pref.UnusedParamMode = UnusedParamStrict
+	man = newStringMan(pref)
+	assert.Nil(t, man.registStatement(stmt))
+	built, err = man.BuildWithStmt("SelectMember", BuildParam{"id": 1, "MemberType": "A"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM MEMBER WHERE ID=1 AND TYPE='A'", built)
+
+	assert.Nil(t, man.registStatement(QueryStatement{Id: "SelectByType", Query: "SELECT * FROM MEMBER WHERE TYPE={MemberType}"}))
+	built, err = man.BuildWithStmt("SelectByType", BuildParam{"member_type": "B"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM MEMBER WHERE TYPE='B'", built)
+}
+
+type pathMember struct {
+	Name    string
+	Address *pathAddress
+	Tags    map[string]interface{}
+	secret  string
+}
+
+type pathAddress struct {
+	City string
+}
+
+type pathOrder struct {
+	Member pathMember
+	Items  []pathItem
+}
+
+type pathItem struct {
+	Sku string
+}
+
+func TestParseParamPath(t *testing.T) {
+	path, err := parseParamPath("Order.Items[0].Sku")
+	assert.Nil(t, err)
+	assert.Equal(t, []paramSegment{{name: "Order", index: -1}, {name: "Items", index: -1}, {index: 0}, {name: "Sku", index: -1}}, path)
+
+	path, err = parseParamPath("Name")
+	assert.Nil(t, err)
+	assert.Nil(t, path)
+
+	for _, invalid := range []string{"User..Name", "User.", ".User", "Items[a]", "Items[0", "[0].Sku"} {
+		_, err = parseParamPath(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestNestedParams(t *testing.T) {
+	man := newStringMan(NewStringmanPreference(""))
+	assert.Nil(t, man.registStatement(QueryStatement{Id: "InsertOrder",
+		Query: "INSERT INTO ORDERS VALUES ({Order.Member.Name}, {Order.Items[1].Sku}, {Order.Member.Address.City?}, {Order.Member.Tags.grade.level=0})"}))
+
+	order := &pathOrder{
+		Member: pathMember{Name: "fatima", Tags: map[string]interface{}{"grade": map[string]int{"level": 3}}},
+		Items:  []pathItem{{Sku: "A-1"}, {Sku: "B-2"}},
+	}
+	built, err := man.BuildWithStmt("InsertOrder", BuildParam{"Order": order})
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO ORDERS VALUES ('fatima', 'B-2', null, 3)", built)
+
+	order.Member.Address = &pathAddress{City: "seoul"}
+	order.Member.Tags = nil
+	built, err = man.BuildWithStmt("InsertOrder", BuildParam{"Order": order})
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO ORDERS VALUES ('fatima', 'B-2', 'seoul', 0)", built)
+
+	order.Items = order.Items[:1]
+	_, err = man.BuildWithStmt("InsertOrder", BuildParam{"Order": order})
+	assert.EqualError(t, err, "param Order.Items[1].Sku : index 1 out of range of Order.Items (len=1)")
+
+	assert.Nil(t, man.registStatement(QueryStatement{Id: "SelectMember", Query: "SELECT * FROM MEMBER WHERE NAME={Member.Nmae}"}))
+	_, err = man.BuildWithStmt("SelectMember", BuildParam{"Member": pathMember{}})
+	assert.EqualError(t, err, "param Member.Nmae : field Nmae not found in Member (stringman.pathMember)")
+
+	assert.Nil(t, man.registStatement(QueryStatement{Id: "SelectSecret", Query: "SELECT * FROM MEMBER WHERE SECRET={Member.secret}"}))
+	_, err = man.BuildWithStmt("SelectSecret", BuildParam{"Member": pathMember{}})
+	assert.NotNil(t, err)
+
+	assert.Nil(t, man.registStatement(QueryStatement{Id: "SelectCity", Query: "SELECT * FROM CITY WHERE NAME={Member.Address.City}"}))
+	_, err = man.BuildWithStmt("SelectCity", BuildParam{"Member": pathMember{}})
+	assert.EqualError(t, err, "not found param Member.Address.City : Member.Address is nil")
+
+	// whole name is preferred
+	built, err = man.BuildWithStmt("SelectCity", BuildParam{"Member.Address.City": "busan"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM CITY WHERE NAME='busan'", built)
+
+	pref := NewStringmanPreference("")
+	pref.UnusedParamMode = UnusedParamStrict
+	strict := newStringMan(pref)
+	assert.Nil(t, strict.registStatement(QueryStatement{Id: "Greeting", Query: "{Member.Tags.gender, select, female{She} other{They}} is {Member.Name}"}))
+	text, err := strict.FormatNamed("Greeting", map[string]interface{}{"Member": pathMember{Name: "fatima", Tags: map[string]interface{}{"gender": "female"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, "She is fatima", text)
+
+	assert.NotNil(t, man.registStatement(QueryStatement{Id: "Broken", Query: "SELECT {Member..Name}"}))
+}
@@ -0,0 +1,259 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	extXml  = ".xml"
+	extYaml = ".yaml"
+	extYml  = ".yml"
+	extJson = ".json"
+	extSql  = ".sql"
+)
+
+type statementParser func(data []byte) ([]QueryStatement, error)
+
+var statementParsers = map[string]statementParser{
+	extXml:  parseWithSax,
+	extYaml: parseYaml,
+	extYml:  parseYaml,
+	extJson: parseJson,
+	extSql:  parseSql,
+}
+
+func findStatementParser(file string) (statementParser, bool) {
+	parser, ok := statementParsers[strings.ToLower(filepath.Ext(file))]
+	return parser, ok
+}
+
+// parseStatementFile parses statements with parser chosen by file extension
+func parseStatementFile(file string, data []byte) ([]QueryStatement, error) {
+	parser, ok := findStatementParser(file)
+	if !ok {
+		return nil, fmt.Errorf("unsupported file format : %s", file)
+	}
+	return parser(data)
+}
+
+// statementFile is the document of yaml and json statement file
+//
+//	statements:
+//	  - id: SelectCity
+//	    lang: ko
+//	    text: |
+//	      SELECT * FROM CITY WHERE NAME={Name}
+type statementFile struct {
+	Statements []statementDeclare `json:"statements" yaml:"statements"`
+}
+
+// statementDeclare is a statement with its attributes declared in file
+type statementDeclare struct {
+	Id       string              `json:"id" yaml:"id"`
+	Lang     string              `json:"lang,omitempty" yaml:"lang,omitempty"`
+	Engine   string              `json:"engine,omitempty" yaml:"engine,omitempty"`
+	Allow    map[string][]string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Override bool                `json:"override,omitempty" yaml:"override,omitempty"`
+	Text     string              `json:"text" yaml:"text"`
+}
+
+func (d statementDeclare) toStatement() (QueryStatement, error) {
+	stmt := QueryStatement{}
+	stmt.Id = d.Id
+	if len(stmt.Id) == 0 {
+		return stmt, fmt.Errorf("statement without id")
+	}
+	stmt.Query = strings.Trim(d.Text, cutset)
+	stmt.columnMention = make([]ColumnBind, 0)
+	stmt.allowList = make(map[string][]string)
+	for k, v := range d.Allow {
+		stmt.allowList[k] = v
+	}
+	stmt.engine = d.Engine
+	stmt.lang = normalizeLocale(d.Lang)
+	stmt.override = d.Override
+	return stmt, nil
+}
+
+func (f statementFile) toStatements() ([]QueryStatement, error) {
+	list := make([]QueryStatement, 0, len(f.Statements))
+	for _, d := range f.Statements {
+		stmt, err := d.toStatement()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, stmt)
+	}
+	return list, nil
+}
+
+func parseYaml(data []byte) ([]QueryStatement, error) {
+	file := statementFile{}
+	err := yaml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+	return file.toStatements()
+}
+
+func parseJson(data []byte) ([]QueryStatement, error) {
+	file := statementFile{}
+	err := json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+	return file.toStatements()
+}
+
+const (
+	sqlCommentPrefix = "--"
+	sqlNameDirective = "name:"
+	sqlMetaDirective = "meta:"
+	sqlMetaAllow     = "allow."
+)
+
+// parseSql parses statements delimited by name comment. lines before first name comment are ignored
+//
+//	-- name: SelectShardTrack
+//	-- meta: allow.Sort=track_id,create_time
+//	SELECT * FROM ted_track_temp_${Shard} ORDER BY ${Sort}
+func parseSql(data []byte) ([]QueryStatement, error) {
+	declares := make([]statementDeclare, 0)
+	var current *statementDeclare
+	var text bytes.Buffer
+	flush := func() {
+		if current != nil {
+			current.Text = text.String()
+			declares = append(declares, *current)
+		}
+		text.Reset()
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		directive, value, ok := parseSqlDirective(line)
+		switch {
+		case ok && directive == sqlNameDirective:
+			flush()
+			id, err := sqlStatementName(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d : %s", lineNo, err.Error())
+			}
+			current = &statementDeclare{Id: id}
+		case ok && directive == sqlMetaDirective:
+			if current == nil {
+				return nil, fmt.Errorf("line %d : meta before statement name", lineNo)
+			}
+			err := current.applyMeta(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d : %s", lineNo, err.Error())
+			}
+		case current != nil:
+			text.WriteString(line)
+			text.WriteByte('\n')
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return statementFile{Statements: declares}.toStatements()
+}
+
+// sqlStatementName returns statement id of name comment. sqlc style command like ":one" is ignored
+// e.g) "GetAuthor :one" => GetAuthor
+func sqlStatementName(value string) (string, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 2 && strings.HasPrefix(fields[1], ":") {
+		fields = fields[:1]
+	}
+
+	switch len(fields) {
+	case 0:
+		return "", fmt.Errorf("empty statement name")
+	case 1:
+		return fields[0], nil
+	}
+	return "", fmt.Errorf("statement name has whitespace [%s]", value)
+}
+
+// parseSqlDirective returns directive and its value from comment like "-- name: X"
+func parseSqlDirective(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, sqlCommentPrefix) {
+		return "", "", false
+	}
+	line = strings.TrimSpace(line[len(sqlCommentPrefix):])
+	for _, directive := range []string{sqlNameDirective, sqlMetaDirective} {
+		if strings.HasPrefix(line, directive) {
+			return directive, strings.TrimSpace(line[len(directive):]), true
+		}
+	}
+	return "", "", false
+}
+
+// applyMeta sets attributes from meta value like "lang=en engine=template override=true allow.Sort=name,create_time"
+func (d *statementDeclare) applyMeta(meta string) error {
+	for _, field := range strings.Fields(meta) {
+		pair := strings.SplitN(field, "=", 2)
+		if len(pair) != 2 || len(pair[1]) == 0 {
+			return fmt.Errorf("invalid meta : %s", field)
+		}
+		key, value := pair[0], pair[1]
+		switch {
+		case key == attrLang:
+			d.Lang = value
+		case key == attrEngine:
+			d.Engine = value
+		case key == attrOverride:
+			d.Override = value == "true"
+		case strings.HasPrefix(key, sqlMetaAllow) && len(key) > len(sqlMetaAllow):
+			if d.Allow == nil {
+				d.Allow = make(map[string][]string)
+			}
+			param := key[len(sqlMetaAllow):]
+			for _, v := range strings.Split(value, allowSeparator) {
+				if len(v) > 0 {
+					d.Allow[param] = append(d.Allow[param], v)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown meta : %s", key)
+		}
+	}
+	return nil
+}
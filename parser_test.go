@@ -0,0 +1,175 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var parserYaml = []byte(`
+statements:
+  - id: SelectShardTrackYaml
+    allow:
+      Sort: [track_id, create_time]
+    text: |
+      SELECT * FROM ted_track_temp_${Shard} ORDER BY ${Sort}
+  - id: GreetingYaml
+    lang: en
+    text: Hello %s
+`)
+
+var parserJson = []byte(`
+{
+  "statements": [
+    {"id": "SelectCityJson", "text": "SELECT * FROM CITY WHERE NAME={Name}"},
+    {"id": "SelectCityTemplateJson", "engine": "template", "text": "SELECT * FROM {{ident .Table}}"}
+  ]
+}
+`)
+
+var parserXml = []byte(`
+<?xml version="1.0" encoding="UTF-8" ?>
+<query>
+	<text id="SelectCityXml">
+		SELECT * FROM CITY WHERE NAME={Name}
+	</text>
+</query>
+`)
+
+func TestParseStatementFile(t *testing.T) {
+	list, err := parseStatementFile("string.yml", parserYaml)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, "SelectShardTrackYaml", list[0].Id)
+	assert.Equal(t, "SELECT * FROM ted_track_temp_${Shard} ORDER BY ${Sort}", list[0].Query)
+	assert.Equal(t, []string{"track_id", "create_time"}, list[0].allowList["Sort"])
+	assert.Equal(t, "en", list[1].Lang())
+
+	_, err = parseStatementFile("string.json", []byte(`{"statements": [{"text": "SELECT 1"}]}`))
+	assert.NotNil(t, err)
+
+	_, err = parseStatementFile("string.txt", []byte("SELECT 1"))
+	assert.NotNil(t, err)
+}
+
+func TestLoadMixedFormats(t *testing.T) {
+	dir, err := os.MkdirTemp("", "stringman-parser")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.a.yaml"), parserYaml, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.b.json"), parserJson, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.c.xml"), parserXml, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.d.sql"), parserSql, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "string.e.txt"), []byte("ignored"), 0644))
+
+	pref := NewStringmanPreference(dir)
+	pref.Fileset = "string*"
+	man, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	query, err := man.BuildWithStmt("SelectShardTrackYaml", BuildParam{"Shard": 3, "Sort": "create_time"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM ted_track_temp_3 ORDER BY create_time", query)
+
+	query, err = man.BuildWithStmt("SelectCityJson", BuildParam{"Name": "seoul"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM CITY WHERE NAME='seoul'", query)
+
+	query, err = man.BuildWithStmt("SelectCityXml", BuildParam{"Name": "seoul"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM CITY WHERE NAME='seoul'", query)
+
+	query, err = man.Render("SelectCityTemplateJson", map[string]interface{}{"Table": "CITY"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM CITY", query)
+
+	text, err := man.FormatLocale("en", "GreetingYaml", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello fatima", text)
+
+	query, err = man.BuildWithStmt("SelectShardTrackSql", BuildParam{"Shard": 3, "Sort": "track_id"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT *\n  FROM ted_track_temp_3\n ORDER BY track_id", query)
+
+	text, err = man.FormatNamedLocale("en", "GreetingSql", map[string]interface{}{"Name": "fatima"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello fatima", text)
+}
+
+var parserSql = []byte(`-- statements for track
+-- name: SelectShardTrackSql
+-- meta: allow.Sort=track_id,create_time
+SELECT *
+  FROM ted_track_temp_${Shard}
+ ORDER BY ${Sort}
+
+-- name: GreetingSql
+-- meta: lang=en
+Hello {Name}
+`)
+
+func TestParseSql(t *testing.T) {
+	list, err := parseStatementFile("query.sql", parserSql)
+	if !assert.Nil(t, err) {
+		return
+	}
+	if !assert.Equal(t, 2, len(list)) {
+		return
+	}
+	assert.Equal(t, "SelectShardTrackSql", list[0].Id)
+	assert.Equal(t, "SELECT *\n  FROM ted_track_temp_${Shard}\n ORDER BY ${Sort}", list[0].Query)
+	assert.Equal(t, []string{"track_id", "create_time"}, list[0].allowList["Sort"])
+	assert.Equal(t, "GreetingSql", list[1].Id)
+	assert.Equal(t, "en", list[1].Lang())
+	assert.Equal(t, "Hello {Name}", list[1].Query)
+
+	_, err = parseSql([]byte("-- name: A\n-- meta: color=red\nSELECT 1"))
+	assert.NotNil(t, err)
+
+	_, err = parseSql([]byte("-- meta: lang=en\n-- name: A\nSELECT 1"))
+	assert.NotNil(t, err)
+
+	_, err = parseSql([]byte("-- name:\nSELECT 1"))
+	assert.NotNil(t, err)
+
+	list, err = parseSql([]byte("-- name: GetAuthor :one\nSELECT * FROM authors WHERE id={Id}"))
+	if assert.Nil(t, err) && assert.Equal(t, 1, len(list)) {
+		assert.Equal(t, "GetAuthor", list[0].Id)
+	}
+
+	_, err = parseSql([]byte("SELECT 1\n-- name: Get Author\nSELECT 1"))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "line 2")
+	}
+}
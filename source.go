@@ -0,0 +1,266 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"sync/atomic"
+	"time"
+)
+
+// Source provides statements of a layer. statements of later source replace same id of earlier source
+// only when they are declared with override
+type Source interface {
+	Layer() string
+	Load() ([]QueryStatement, error)
+}
+
+// RefreshableSource is checked periodically when StringmanPreference.RefreshInterval is set.
+// every source is loaded again when any of them has changed
+type RefreshableSource interface {
+	Source
+	Changed() (bool, error)
+}
+
+// ParseStatements parses statement file data with parser chosen by extension of name.
+// name is recorded as source of statements. locale of name like string.en.xml is applied
+// when loaded by StringMan with StringmanPreference.FileLocales
+func ParseStatements(name string, data []byte) ([]QueryStatement, error) {
+	list, err := parseStatementFile(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse file[%s] : %s", name, err.Error())
+	}
+
+	for i := range list {
+		list[i].source = name
+	}
+	return list, nil
+}
+
+type fileSource struct {
+	layer   string
+	path    string
+	fileset string
+}
+
+// NewFileSource returns source of statement files matched with fileset in path
+func NewFileSource(layer string, path string, fileset string) Source {
+	return fileSource{layer: layer, path: path, fileset: fileset}
+}
+
+func (s fileSource) Layer() string {
+	return s.layer
+}
+
+func (s fileSource) Load() ([]QueryStatement, error) {
+	files, err := findStatementFiles(s.path, s.fileset)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := make([]QueryStatement, 0)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("fail to read file[%s] : %s", file, err.Error())
+		}
+
+		list, err := ParseStatements(file, data)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, list...)
+	}
+	return statements, nil
+}
+
+type fsSource struct {
+	layer   string
+	fsys    fs.FS
+	pattern string
+}
+
+// NewFSSource returns source of statement files matched with pattern in fsys. e.g) embed.FS
+func NewFSSource(layer string, fsys fs.FS, pattern string) Source {
+	return fsSource{layer: layer, fsys: fsys, pattern: pattern}
+}
+
+func (s fsSource) Layer() string {
+	return s.layer
+}
+
+func (s fsSource) Load() ([]QueryStatement, error) {
+	matches, err := fs.Glob(s.fsys, s.pattern)
+	if err != nil {
+		return nil, fmt.Errorf("fail to search statement file : %s [glob=%s]", err.Error(), s.pattern)
+	}
+
+	statements := make([]QueryStatement, 0)
+	for _, file := range filterStatementFiles(matches) {
+		data, err := fs.ReadFile(s.fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("fail to read file[%s] : %s", file, err.Error())
+		}
+
+		list, err := ParseStatements(file, data)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, list...)
+	}
+	return statements, nil
+}
+
+// Reload loads every source into new catalog and replaces current catalog at once.
+// current catalog is kept when any source fails
+func (man *StringMan) Reload() error {
+	return man.loader.reload()
+}
+
+// statementLoader builds catalog from sources. it is shared by StringMan and refresh goroutine
+// and never refers StringMan so that refreshing does not keep StringMan reachable
+type statementLoader struct {
+	preference StringmanPreference
+	encoders   *encoderRegistry
+	catalog    *atomic.Value // *statementCatalog. replaced as a whole on reload
+	sources    []Source
+}
+
+func newStatementLoader(pref StringmanPreference, encoders *encoderRegistry) *statementLoader {
+	loader := &statementLoader{}
+	loader.preference = pref
+	loader.encoders = encoders
+	loader.catalog = &atomic.Value{}
+	loader.catalog.Store(newStatementCatalog())
+	return loader
+}
+
+func (l *statementLoader) statements() *statementCatalog {
+	return l.catalog.Load().(*statementCatalog)
+}
+
+// stagedSource keeps loaded data pending until catalog is replaced.
+// e.g) HttpSource does not move its etag and cache to bundle which fails to register
+type stagedSource interface {
+	commit() error
+	discard()
+}
+
+func (l *statementLoader) reload() error {
+	statements, err := l.loadSources()
+	if err != nil {
+		l.discardSources()
+		return err
+	}
+
+	l.catalog.Store(statements)
+	l.commitSources()
+	return nil
+}
+
+func (l *statementLoader) loadSources() (*statementCatalog, error) {
+	statements := newStatementCatalog()
+	for _, source := range l.sources {
+		list, err := source.Load()
+		if err != nil {
+			return nil, fmt.Errorf("fail to load source [layer=%s] : %s", source.Layer(), err.Error())
+		}
+
+		for _, v := range list {
+			v.layer = source.Layer()
+			err = l.registStatementTo(statements, v)
+			if err != nil {
+				return nil, fmt.Errorf("fail to regist statement [layer=%s] : %s", source.Layer(), err.Error())
+			}
+		}
+	}
+	return statements, nil
+}
+
+// commitSources lets staged sources adopt loaded data. failure is only logged since catalog is already replaced
+func (l *statementLoader) commitSources() {
+	for _, source := range l.sources {
+		staged, ok := source.(stagedSource)
+		if !ok {
+			continue
+		}
+		err := staged.commit()
+		if err != nil && l.preference.DebugLogger != nil {
+			l.preference.DebugLogger.Printf("fail to commit source [layer=%s] : %s", source.Layer(), err.Error())
+		}
+	}
+}
+
+func (l *statementLoader) discardSources() {
+	for _, source := range l.sources {
+		if staged, ok := source.(stagedSource); ok {
+			staged.discard()
+		}
+	}
+}
+
+func (l *statementLoader) refreshLoop(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			err := l.refresh()
+			if err != nil {
+				l.preference.DebugLogger.Printf("fail to refresh stringman : %s", err.Error())
+			}
+		}
+	}
+}
+
+// refresh reloads sources when any RefreshableSource has changed.
+// source which fails to check does not prevent reloading changes of other sources
+func (l *statementLoader) refresh() error {
+	changed := false
+	var checkErr error
+	for _, source := range l.sources {
+		refreshable, ok := source.(RefreshableSource)
+		if !ok {
+			continue
+		}
+
+		c, err := refreshable.Changed()
+		if err != nil && checkErr == nil {
+			checkErr = fmt.Errorf("fail to check source [layer=%s] : %s", source.Layer(), err.Error())
+		}
+		changed = changed || c
+	}
+
+	if changed {
+		err := l.reload()
+		if err != nil {
+			return err
+		}
+	}
+	return checkErr
+}
@@ -0,0 +1,109 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"runtime"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"resources/string.xml":    {Data: []byte(`<query><text id="SelectCity">SELECT * FROM CITY WHERE NAME={Name}</text></query>`)},
+		"resources/string.en.sql": {Data: []byte("-- name: Greeting\nHello %s\n")},
+		"resources/readme.txt":    {Data: []byte("ignored")},
+	}
+
+	pref := NewStringmanPreference("")
+	pref.Fileset = "none"
+	pref.FileLocales = []string{"en"}
+	pref.Sources = []Source{NewFSSource("embed", fsys, "resources/*")}
+	man, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	query, err := man.BuildWithStmt("SelectCity", BuildParam{"Name": "seoul"})
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM CITY WHERE NAME='seoul'", query)
+
+	text, err := man.FormatLocale("en", "Greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello fatima", text)
+
+	stmt, err := man.find("SelectCity")
+	assert.Nil(t, err)
+	assert.Equal(t, "embed", stmt.Layer())
+	assert.Equal(t, "resources/string.xml", stmt.source)
+	assert.Equal(t, []string{"en"}, man.Locales())
+}
+
+type countingSource struct {
+	checked chan struct{}
+}
+
+func (s countingSource) Layer() string {
+	return "counting"
+}
+
+func (s countingSource) Load() ([]QueryStatement, error) {
+	return []QueryStatement{{Id: "SelectCity", Query: "SELECT * FROM {{ident .Table}}", engine: engineTemplate}}, nil
+}
+
+func (s countingSource) Changed() (bool, error) {
+	select {
+	case s.checked <- struct{}{}:
+	default:
+	}
+	return false, nil
+}
+
+// refresh goroutine must not keep StringMan reachable. finalizer closes it
+func TestRefreshStopsWithFinalizer(t *testing.T) {
+	pref := NewStringmanPreference("")
+	pref.Fileset = "none"
+	pref.RefreshInterval = time.Millisecond
+	source := countingSource{checked: make(chan struct{})}
+	pref.Sources = []Source{source}
+	man, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+	stop := man.stopRefresh
+	<-source.checked
+	man = nil
+
+	for i := 0; i < 100; i++ {
+		runtime.GC()
+		select {
+		case <-stop:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Errorf("unreferenced StringMan is not finalized")
+}
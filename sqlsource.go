@@ -0,0 +1,116 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+const (
+	sqlSourceSelectQuery  = "SELECT id, text, locale, version FROM %s"
+	sqlSourceVersionQuery = "SELECT COUNT(*), MAX(version) FROM %s"
+)
+
+// SqlSource reads statements from rows of (id, text, locale, version) table.
+// locale could be null for default statement. rows are loaded again when
+// row count or max version has changed
+type SqlSource struct {
+	layer   string
+	db      *sql.DB
+	table   string
+	mutex   sync.Mutex
+	count   int64
+	version int64
+}
+
+// NewSqlSource returns source of statements stored in table
+func NewSqlSource(layer string, db *sql.DB, table string) (*SqlSource, error) {
+	if !identifierRegex.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name : %s", table)
+	}
+
+	return &SqlSource{layer: layer, db: db, table: table}, nil
+}
+
+func (s *SqlSource) Layer() string {
+	return s.layer
+}
+
+func (s *SqlSource) Load() ([]QueryStatement, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rows, err := s.db.Query(fmt.Sprintf(sqlSourceSelectQuery, s.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var count, version int64
+	statements := make([]QueryStatement, 0)
+	for rows.Next() {
+		var declare statementDeclare
+		var locale sql.NullString
+		var rowVersion int64
+		err = rows.Scan(&declare.Id, &declare.Text, &locale, &rowVersion)
+		if err != nil {
+			return nil, err
+		}
+		declare.Lang = locale.String
+
+		stmt, err := declare.toStatement()
+		if err != nil {
+			return nil, err
+		}
+		stmt.source = s.table
+		statements = append(statements, stmt)
+
+		count++
+		if rowVersion > version {
+			version = rowVersion
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	s.count = count
+	s.version = version
+	return statements, nil
+}
+
+// Changed reports whether row count or max version differs from last Load
+func (s *SqlSource) Changed() (bool, error) {
+	var count int64
+	var version sql.NullInt64
+	err := s.db.QueryRow(fmt.Sprintf(sqlSourceVersionQuery, s.table)).Scan(&count, &version)
+	if err != nil {
+		return false, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return count != s.count || version.Int64 != s.version, nil
+}
@@ -0,0 +1,199 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver serves rows of in-memory statement table for SqlSource
+type fakeDriver struct {
+	mutex sync.Mutex
+	rows  [][]driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return fakeConn{driver: d}, nil
+}
+
+func (d *fakeDriver) setRows(rows [][]driver.Value) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.rows = rows
+}
+
+func (d *fakeDriver) query(query string) (driver.Rows, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "SELECT id, text, locale, version FROM "):
+		return &fakeRows{columns: []string{"id", "text", "locale", "version"}, rows: d.rows}, nil
+	case strings.HasPrefix(query, "SELECT COUNT(*), MAX(version) FROM "):
+		var version driver.Value
+		for _, r := range d.rows {
+			if version == nil || r[3].(int64) > version.(int64) {
+				version = r[3]
+			}
+		}
+		return &fakeRows{columns: []string{"count", "version"}, rows: [][]driver.Value{{int64(len(d.rows)), version}}}, nil
+	}
+	return nil, fmt.Errorf("unsupported query : %s", query)
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{driver: c.driver, query: query}, nil
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+type fakeStmt struct {
+	driver *fakeDriver
+	query  string
+}
+
+func (s fakeStmt) Close() error {
+	return nil
+}
+
+func (s fakeStmt) NumInput() int {
+	return 0
+}
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.driver.query(s.query)
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	pos     int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+var sqlSourceDriver = &fakeDriver{}
+
+func init() {
+	sql.Register("stringman-fake", sqlSourceDriver)
+}
+
+func TestSqlSource(t *testing.T) {
+	sqlSourceDriver.setRows([][]driver.Value{
+		{"Greeting", "안녕하세요 %s", nil, int64(1)},
+		{"Greeting", "Hello %s", "en", int64(1)},
+	})
+
+	db, err := sql.Open("stringman-fake", "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer db.Close()
+
+	source, err := NewSqlSource("message", db, "MESSAGE_TEXT")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	pref := NewStringmanPreference("")
+	pref.Fileset = "none"
+	pref.Sources = []Source{source}
+	man, err := NewStringman(pref)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer man.Close()
+
+	text, err := man.FormatLocale("en-US", "Greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello fatima", text)
+
+	stmt, err := man.find("Greeting")
+	assert.Nil(t, err)
+	assert.Equal(t, "message", stmt.Layer())
+
+	changed, err := source.Changed()
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	sqlSourceDriver.setRows([][]driver.Value{
+		{"Greeting", "반갑습니다 %s", nil, int64(2)},
+		{"Greeting", "Hello %s", "en", int64(1)},
+	})
+	assert.Nil(t, man.loader.refresh())
+
+	text, err = man.FormatWithStmt("Greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "반갑습니다 fatima", text)
+
+	// catalog is kept when reloading fails
+	sqlSourceDriver.setRows([][]driver.Value{
+		{"Greeting", "반갑습니다 %s", nil, int64(3)},
+		{"Greeting", "Hi %s", nil, int64(3)},
+	})
+	assert.NotNil(t, man.loader.refresh())
+
+	text, err = man.FormatWithStmt("Greeting", "fatima")
+	assert.Nil(t, err)
+	assert.Equal(t, "반갑습니다 fatima", text)
+
+	_, err = NewSqlSource("message", db, "MESSAGE; DROP")
+	assert.NotNil(t, err)
+}
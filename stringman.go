@@ -29,11 +29,14 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
-var queryNormalizer QueryNormalizer
+// queryNormalizer is created once at init. statements are built from refresh goroutines
+var queryNormalizer QueryNormalizer = newNormalizer()
 
 type QueryNormalizer interface {
 	normalize(stmt *QueryStatement) error
@@ -42,8 +45,39 @@ type QueryNormalizer interface {
 
 type StringMan struct {
 	preference         StringmanPreference
-	statementMap       map[string]QueryStatement
+	loader             *statementLoader
 	fieldNameConverter FieldNameConvertStrategy
+	encoders           *encoderRegistry
+	callerCache        *sync.Map
+	stopRefresh        chan struct{}
+	closeOnce          *sync.Once
+}
+
+// statementCatalog holds default statements and statements of each locale
+type statementCatalog struct {
+	statementMap map[string]QueryStatement
+	localeMap    map[string]map[string]QueryStatement
+}
+
+func newStatementCatalog() *statementCatalog {
+	catalog := &statementCatalog{}
+	catalog.statementMap = make(map[string]QueryStatement)
+	catalog.localeMap = make(map[string]map[string]QueryStatement)
+	return catalog
+}
+
+func (c *statementCatalog) localeCatalog(locale string) map[string]QueryStatement {
+	catalog, ok := c.localeMap[locale]
+	if !ok {
+		catalog = make(map[string]QueryStatement)
+		c.localeMap[locale] = catalog
+	}
+	return catalog
+}
+
+// statements returns current catalog
+func (man *StringMan) statements() *statementCatalog {
+	return man.loader.statements()
 }
 
 func (s StringMan) String() string {
@@ -51,7 +85,7 @@ func (s StringMan) String() string {
 	buffer.WriteString(fmt.Sprintf("path=[%s]", s.preference.queryFilePath))
 	buffer.WriteString(fmt.Sprintf(",fileSet=[%s]", s.preference.Fileset))
 	buffer.WriteString(",keys=[")
-	for k, _ := range s.statementMap {
+	for k, _ := range s.statements().statementMap {
 		buffer.WriteString(",")
 		buffer.WriteString(k)
 	}
@@ -61,36 +95,60 @@ func (s StringMan) String() string {
 }
 
 func (man *StringMan) registStatement(queryStatement QueryStatement) error {
-	if man.preference.Debug {
-		man.preference.DebugLogger.Printf("registStatement stmt : %s", queryStatement)
+	return man.loader.registStatementTo(man.statements(), queryStatement)
+}
+
+func (l *statementLoader) registStatementTo(statements *statementCatalog, queryStatement QueryStatement) error {
+	if l.preference.Debug {
+		l.preference.DebugLogger.Printf("registStatement stmt : %s", queryStatement)
+	}
+	queryStatement.raw = queryStatement.Query
+	if len(queryStatement.lang) == 0 {
+		queryStatement.lang = fileLocale(queryStatement.source, l.preference.FileLocales)
 	}
-	queryStatement, err := man.buildStatement(queryStatement)
+	queryStatement, err := l.buildStatement(queryStatement)
 	if err != nil {
 		return err
 	}
 
-	if man.preference.Debug {
-		man.preference.DebugLogger.Printf("registStatement stmt (after build) : %s", queryStatement)
+	if !queryStatement.IsTemplate() {
+		queryStatement.format = parseFormatSpec(queryStatement.Query)
+	}
+	if l.preference.StrictFormat && !queryStatement.IsTemplate() && len(queryStatement.columnMention) == 0 && queryStatement.format.err != nil {
+		return fmt.Errorf("invalid format text [%s] : %s", queryStatement.Id, queryStatement.format.err.Error())
+	}
+
+	if l.preference.Debug {
+		l.preference.DebugLogger.Printf("registStatement stmt (after build) : %s", queryStatement)
 	}
 	id := strings.ToUpper(queryStatement.Id)
-	if _, exists := man.statementMap[id]; exists {
+	catalog := statements.statementMap
+	if len(queryStatement.lang) > 0 {
+		catalog = statements.localeCatalog(queryStatement.lang)
+	}
+	if prev, exists := catalog[id]; exists && (!queryStatement.override || prev.layer == queryStatement.layer) {
+		if len(queryStatement.lang) > 0 {
+			return fmt.Errorf("duplicated user statement id : [%s] lang=%s", id, queryStatement.lang)
+		}
 		return fmt.Errorf("duplicated user statement id : [%s]", id)
 	}
 
-	man.statementMap[id] = queryStatement
-	if man.preference.Debug {
-		man.preference.DebugLogger.Printf("map regist : %s", id)
+	catalog[id] = queryStatement
+	if l.preference.Debug {
+		l.preference.DebugLogger.Printf("map regist : %s (lang=%s,layer=%s,override=%v)", id, queryStatement.lang, queryStatement.layer, queryStatement.override)
 	}
 
 	return nil
 }
 
-func (man *StringMan) buildStatement(queryStatement QueryStatement) (QueryStatement, error) {
-	if queryNormalizer == nil {
-		queryNormalizer = newNormalizer()
-		if queryNormalizer == nil {
-			return queryStatement, fmt.Errorf("not found normalizer")
-		}
+func (l *statementLoader) buildStatement(queryStatement QueryStatement) (QueryStatement, error) {
+	switch queryStatement.engine {
+	case "":
+	case engineTemplate:
+		err := l.compileTemplate(&queryStatement)
+		return queryStatement, err
+	default:
+		return queryStatement, fmt.Errorf("unknown engine %s for %s", queryStatement.engine, queryStatement.Id)
 	}
 
 	err := queryNormalizer.normalize(&queryStatement)
@@ -102,7 +160,7 @@ func (man *StringMan) buildStatement(queryStatement QueryStatement) (QueryStatem
 }
 
 func (man *StringMan) find(id string) (QueryStatement, error) {
-	stmt, ok := man.statementMap[strings.ToUpper(id)]
+	stmt, ok := man.statements().statementMap[strings.ToUpper(id)]
 	if !ok {
 		return stmt, fmt.Errorf("not found text statement for id : %s", id)
 	}
@@ -110,11 +168,24 @@ func (man *StringMan) find(id string) (QueryStatement, error) {
 	return stmt, nil
 }
 
+// Statements returns all loaded statements ordered by id
+func (man *StringMan) Statements() []QueryStatement {
+	statementMap := man.statements().statementMap
+	list := make([]QueryStatement, 0, len(statementMap))
+	for _, v := range statementMap {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Id < list[j].Id
+	})
+	return list
+}
+
 type BuildParam map[string]interface{}
 
 func (man *StringMan) Build(param BuildParam) (string, error) {
 	pc, _, _, _ := runtime.Caller(1)
-	funcName := findFunctionName(pc)
+	funcName := man.callerId(pc)
 	return man.BuildWithStmt(funcName, param)
 }
 
@@ -124,19 +195,31 @@ func (man *StringMan) BuildWithStmt(stmtIdOrUserQuery string, param BuildParam)
 		return "", err
 	}
 
+	if stmt.IsTemplate() {
+		return "", fmt.Errorf("%s is template statement. use Render", stmtIdOrUserQuery)
+	}
+
+	params := man.newParamIndex(param)
+	err = man.checkUnusedParams(stmt, params)
+	if err != nil {
+		return "", err
+	}
+
 	if param == nil || len(param) == 0 {
-		if len(stmt.columnMention) != 0 {
+		if len(stmt.columnMention) == 0 {
+			return stmt.Query, nil
+		}
+		if stmt.hasRequiredColumn() {
 			return stmt.Query, fmt.Errorf("need parameter for completing text")
 		}
-		return stmt.Query, nil
 	}
 
-	return completeText(stmt, param)
+	return man.completeText(stmt, params, renderSql, man.statementLocale(stmt))
 }
 
 func (man *StringMan) Format(param ...interface{}) (string, error) {
 	pc, _, _, _ := runtime.Caller(1)
-	funcName := findFunctionName(pc)
+	funcName := man.callerId(pc)
 	return man.FormatWithStmt(funcName, param...)
 }
 
@@ -146,31 +229,122 @@ func (man *StringMan) FormatWithStmt(stmtIdOrUserQuery string, param ...interfac
 		return "", err
 	}
 
+	return man.formatStatement(stmt, param...)
+}
+
+func (man *StringMan) formatStatement(stmt QueryStatement, param ...interface{}) (string, error) {
+	if stmt.IsTemplate() {
+		return "", fmt.Errorf("%s is template statement. use Render", stmt.Id)
+	}
+
+	err := stmt.format.check(param, man.preference.StrictFormat)
+	if err != nil {
+		return "", fmt.Errorf("fail to format %s : %s", stmt.Id, err.Error())
+	}
+
 	return fmt.Sprintf(stmt.Query, param...), nil
 }
 
+// FormatNamed completes text with named parameters like {UserName}.
+// unlike Build, values are written as plain text without sql quoting
+func (man *StringMan) FormatNamed(stmtId string, param map[string]interface{}) (string, error) {
+	stmt, err := man.find(stmtId)
+	if err != nil {
+		return "", err
+	}
+
+	if stmt.IsTemplate() {
+		return "", fmt.Errorf("%s is template statement. use Render", stmtId)
+	}
+
+	params := man.newParamIndex(param)
+	err = man.checkUnusedParams(stmt, params)
+	if err != nil {
+		return "", err
+	}
+
+	return man.completeText(stmt, params, renderText, man.statementLocale(stmt))
+}
+
+// RegisterEncoder registers literal encoder for type t on this StringMan.
+// registered encoder takes precedence over built-in conversion
+func (man *StringMan) RegisterEncoder(t reflect.Type, encoder Encoder) {
+	man.encoders.register(t, encoder)
+}
+
+// Close stops refreshing sources. StringMan which is not referenced anymore is closed by finalizer
 func (man *StringMan) Close() error {
+	man.closeOnce.Do(func() {
+		close(man.stopRefresh)
+	})
 	return nil
 }
 
-func findFunctionName(pc uintptr) string {
-	var funcName = runtime.FuncForPC(pc).Name()
-	var found = strings.LastIndexByte(funcName, '.')
-	if found < 0 {
-		return funcName
-	}
-	return funcName[found+1:]
-}
+const (
+	renderSql = iota
+	renderText
+)
 
-func completeText(stmt QueryStatement, param BuildParam) (string, error) {
+type renderMode uint8
+
+func (man *StringMan) completeText(stmt QueryStatement, params paramIndex, mode renderMode, locale string) (string, error) {
 	queue := list.New()
 
+	render := man.encoders.asString
+	null := nullLiteral
+	if mode == renderText {
+		render = man.encoders.asText
+		null = ""
+	}
+
 	for _, c := range stmt.columnMention {
-		v, ok := param[c.name]
-		if !ok {
-			return stmt.Query, fmt.Errorf("not found param %s", c.name)
+		if c.IsChoice() {
+			str, err := man.completeChoice(c, params, mode, locale)
+			if err != nil {
+				return "", err
+			}
+			queue.PushBack(str)
+			continue
+		}
+
+		v, err := man.resolveParam(params, c)
+		notFound, missing := err.(paramNotFound)
+		if err != nil && !missing {
+			return "", fmt.Errorf("param %s : %s", c.name, err.Error())
+		}
+		ok := err == nil
+		if ok && len(c.filters) > 0 {
+			filtered, err := applyFilters(c.filters, v)
+			if err != nil {
+				return "", fmt.Errorf("%s : %s", c.name, err.Error())
+			}
+			v = filtered
+		}
+		if ok && c.IsRaw() {
+			str, err := asRawString(stmt, c.name, v)
+			if err != nil {
+				return "", err
+			}
+			queue.PushBack(str)
+			continue
+		}
+		if ok {
+			str, err := render(v)
+			if err != nil {
+				return "", err
+			}
+			queue.PushBack(str)
+			continue
+		}
+
+		switch {
+		case c.hasDefault:
+			queue.PushBack(c.defaultValue)
+		case c.optional:
+			queue.PushBack(null)
+		default:
+			return stmt.Query, notFound
 		}
-		queue.PushBack(v)
 	}
 
 	var buffer bytes.Buffer
@@ -182,58 +356,88 @@ func completeText(stmt QueryStatement, param BuildParam) (string, error) {
 			continue
 		}
 		e := queue.Front()
-		str, err := asString(e.Value)
-		if err != nil {
-			return "", err
-		}
-		buffer.WriteString(str)
+		buffer.WriteString(e.Value.(string))
 		queue.Remove(e)
 	}
 
 	return buffer.String(), nil
 }
 
+// asRawString returns unquoted value for raw substitution (${Table}).
+// only identifier or value in allow-list is accepted
+func asRawString(stmt QueryStatement, name string, v interface{}) (string, error) {
+	var str string
+	switch s := v.(type) {
+	case string:
+		str = s
+	case []byte:
+		str = string(s)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		str = fmt.Sprintf("%d", s)
+	default:
+		var r = reflect.TypeOf(s)
+		return "", fmt.Errorf("unsupported raw type %v for %s", r, name)
+	}
+
+	if !stmt.isAllowed(name, str) {
+		return "", fmt.Errorf("not allowed raw value [%s] for %s", str, name)
+	}
+
+	return str, nil
+}
+
 const sqlyyyyMMddHHmmss = "2006-01-02 15:04:05"
 
-func asString(v interface{}) (string, error) {
+func (r *encoderRegistry) asString(v interface{}) (string, error) {
+	if encoder, ok := r.find(reflect.TypeOf(v)); ok {
+		return encoder(v)
+	}
+
 	switch s := v.(type) {
 	case string:
-		return fmt.Sprintf("'%s'", s), nil
+		return r.format.quoteString(s), nil
 	case []byte:
-		return fmt.Sprintf("'%s'", string(s)), nil
+		return r.format.quoteString(string(s)), nil
 	case time.Time:
-		return fmt.Sprintf("'%s'", s.Format(sqlyyyyMMddHHmmss)), nil
+		return fmt.Sprintf("'%s'", r.format.formatTime(s)), nil
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 		return fmt.Sprintf("%d", s), nil
-	case float32, float64:
-		return fmt.Sprintf("%f", s), nil
+	case float32:
+		return r.format.formatFloat(float64(s), 32)
+	case float64:
+		return r.format.formatFloat(s, 64)
+	case nil:
+		return nullLiteral, nil
 	case sql.NullString:
 		if !s.Valid {
-			return "null", nil
-		} else {
-			return fmt.Sprintf("'%s'", s.String), nil
+			return nullLiteral, nil
 		}
+		return r.asString(s.String)
 	case sql.NullInt64:
 		if !s.Valid {
-			return "null", nil
-		} else {
-			return fmt.Sprintf("'%d'", s.Int64), nil
+			return nullLiteral, nil
+		}
+		return r.asString(s.Int64)
+	case sql.NullInt32:
+		if !s.Valid {
+			return nullLiteral, nil
 		}
+		return r.asString(s.Int32)
 	case sql.NullBool:
 		if !s.Valid {
-			return "null", nil
-		} else {
-			if s.Bool {
-				return "'true'", nil
-			}
-			return "'false'", nil
+			return nullLiteral, nil
 		}
+		return r.asString(s.Bool)
 	case sql.NullFloat64:
 		if !s.Valid {
-			return "null", nil
-		} else {
-			return fmt.Sprintf("'%f'", s.Float64), nil
+			return nullLiteral, nil
 		}
+		return r.asString(s.Float64)
+	case sql.NullTime:
+		if !s.Valid {
+			return nullLiteral, nil
+		}
+		return r.asString(s.Time)
 	case bool:
 		if s {
 			return "true", nil
@@ -241,7 +445,6 @@ func asString(v interface{}) (string, error) {
 			return "false", nil
 		}
 	default:
-		var r = reflect.TypeOf(s)
-		return "", fmt.Errorf("unsupported type %v", r)
+		return r.asFallbackString(v)
 	}
 }
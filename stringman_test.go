@@ -115,6 +115,23 @@ create table city (
 <text id="CompleteFormatText">
         hello %s. your level is %d
     </text>
+<text id="SelectShardTrack">
+        <allow param="Sort">track_id, create_time</allow>
+        SELECT * FROM ted_track_temp_${Shard} ORDER BY ${Sort} LIMIT {Limit}
+    </text>
+<text id="SelectCityWithFilter">
+        SELECT * FROM CITY WHERE NAME={Name|upper} AND CREATE_TIME > {Date|date:"2006-01-02"}
+    </text>
+<text id="NotifyTicketReserved">
+        {UserName}님, 예매가 완료되었습니다. (총 {Count}매, {Price|comma}원){Memo?}
+    </text>
+<text id="SelectCityTemplate" engine="template">
+        SELECT * FROM {{ident .Table}} WHERE age > {{sql .Age}}
+        {{- if .Names}} AND name IN ({{list .Names}}){{end}}
+    </text>
+<text id="SelectCityWithDefault">
+        SELECT * FROM CITY WHERE NAME={Name?} LIMIT {Limit=100}
+    </text>
 </query>
 `)
 
@@ -280,3 +297,133 @@ func completeFormatTextWithStmt(t *testing.T, stmtId string) error {
 	assert.Equal(t, "hello fatima-go. your level is 4", built)
 	return nil
 }
+
+func TestDefaultAndOptionalParams(t *testing.T) {
+	built, err := stringManager.BuildWithStmt("selectCityWithDefault", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "SELECT * FROM CITY WHERE NAME=null LIMIT 100", built)
+
+	p := BuildParam{}
+	p["Name"] = "seoul"
+	p["Limit"] = 5
+	built, err = stringManager.BuildWithStmt("selectCityWithDefault", p)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "SELECT * FROM CITY WHERE NAME='seoul' LIMIT 5", built)
+}
+
+func TestParseColumnBind(t *testing.T) {
+	b, err := parseColumnBind("Limit=100", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "Limit", b.Name())
+	assert.Equal(t, "100", b.defaultValue)
+	assert.False(t, b.IsRequired())
+
+	b, err = parseColumnBind("Name?", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "Name", b.Name())
+	assert.True(t, b.optional)
+
+	_, err = parseColumnBind("=100", 1)
+	assert.NotNil(t, err)
+}
+
+func TestRawSubstitution(t *testing.T) {
+	p := BuildParam{}
+	p["Shard"] = 3
+	p["Sort"] = "create_time"
+	p["Limit"] = 10
+	built, err := stringManager.BuildWithStmt("selectShardTrack", p)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "SELECT * FROM ted_track_temp_3 ORDER BY create_time LIMIT 10", built)
+
+	p["Sort"] = "name"
+	_, err = stringManager.BuildWithStmt("selectShardTrack", p)
+	assert.NotNil(t, err)
+
+	p["Sort"] = "track_id"
+	p["Shard"] = "1; DROP TABLE album"
+	_, err = stringManager.BuildWithStmt("selectShardTrack", p)
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "not allowed raw value"))
+	}
+}
+
+func TestFilterParams(t *testing.T) {
+	p := BuildParam{}
+	p["Name"] = "seoul"
+	p["Date"] = time.Date(2024, 12, 1, 12, 0, 0, 0, time.Local)
+	built, err := stringManager.BuildWithStmt("selectCityWithFilter", p)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "SELECT * FROM CITY WHERE NAME='SEOUL' AND CREATE_TIME > '2024-12-01'", built)
+}
+
+func TestFormatNamed(t *testing.T) {
+	p := make(map[string]interface{})
+	p["UserName"] = "홍길동"
+	p["Count"] = 2
+	p["Price"] = 132000
+	built, err := stringManager.FormatNamed("notifyTicketReserved", p)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "홍길동님, 예매가 완료되었습니다. (총 2매, 132,000원)", built)
+
+	delete(p, "UserName")
+	_, err = stringManager.FormatNamed("notifyTicketReserved", p)
+	assert.NotNil(t, err)
+}
+
+func TestRenderTemplate(t *testing.T) {
+	data := map[string]interface{}{
+		"Table": "city",
+		"Age":   20,
+		"Names": []string{"seoul", "busan"},
+	}
+	built, err := stringManager.Render("selectCityTemplate", data)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "SELECT * FROM city WHERE age > 20 AND name IN ('seoul','busan')", built)
+
+	data["Names"] = nil
+	built, err = stringManager.Render("selectCityTemplate", data)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "SELECT * FROM city WHERE age > 20", built)
+
+	data["Age"] = "x' OR '1'='1"
+	data["Names"] = []string{`it's`, `a\' OR 1=1 --`}
+	built, err = stringManager.Render("selectCityTemplate", data)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, `SELECT * FROM city WHERE age > 'x'' OR ''1''=''1' AND name IN ('it''s','a\\'' OR 1=1 --')`, built)
+
+	data["Table"] = "city; drop table city"
+	_, err = stringManager.Render("selectCityTemplate", data)
+	assert.NotNil(t, err)
+
+	_, err = stringManager.BuildWithStmt("selectCityTemplate", BuildParam{})
+	assert.NotNil(t, err)
+
+	_, err = stringManager.Render("updateAlbum", data)
+	assert.NotNil(t, err)
+
+	_, err = stringManager.FormatNamed("selectCityTemplate", data)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "use Render")
+	}
+	_, err = stringManager.FormatNamedLocale("en", "selectCityTemplate", data)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "use Render")
+	}
+}
@@ -0,0 +1,14 @@
+module github.com/fatima-go/stringman/stringmancheck
+
+go 1.22.0
+
+require (
+	github.com/fatima-go/stringman v0.1.0
+	golang.org/x/tools v0.30.0
+)
+
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,408 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+// Package stringmancheck defines an analyzer which verifies that StringMan.Build and StringMan.Format
+// callers have matching statements in xml files.
+//
+// Build and Format find statement with the name of calling function,
+// so renaming go function breaks the lookup only at runtime.
+package stringmancheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fatima-go/stringman"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const (
+	stringmanPath = "github.com/fatima-go/stringman"
+	stringmanType = "StringMan"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "stringmancheck",
+	Doc:      "check that StringMan.Build/Format callers have matching statements and parameters",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var (
+	flagPath    string
+	flagFileset string
+	flagCaller  string
+	flagMatch   string
+)
+
+const (
+	callerLegacy         = "legacy"
+	callerFunction       = "function"
+	callerTypeDot        = "type-dot"
+	callerTypeUnderscore = "type-underscore"
+
+	matchExact      = "exact"
+	matchIgnoreCase = "ignore-case"
+	matchConvert    = "convert"
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&flagPath, "path", ".", "directory of statement files")
+	Analyzer.Flags.StringVar(&flagFileset, "fileset", "string*.xml", "glob pattern of statement files")
+	Analyzer.Flags.StringVar(&flagCaller, "caller", callerLegacy,
+		"caller name mode of StringmanPreference (legacy, function, type-dot, type-underscore)")
+	Analyzer.Flags.StringVar(&flagMatch, "match", matchExact,
+		"parameter match mode of StringmanPreference (exact, ignore-case, convert)")
+}
+
+type catalog struct {
+	once    sync.Once
+	man     *stringman.StringMan
+	columns map[string][]stringman.ColumnBind
+	err     error
+}
+
+var loaded catalog
+
+// loadCatalog loads statement files once for every package analyzed
+func loadCatalog() (map[string][]stringman.ColumnBind, error) {
+	loaded.once.Do(func() {
+		pref := stringman.NewStringmanPreference(flagPath)
+		pref.Fileset = flagFileset
+		switch flagMatch {
+		case matchIgnoreCase:
+			pref.ParamMatchMode = stringman.ParamMatchIgnoreCase
+		case matchConvert:
+			pref.ParamMatchMode = stringman.ParamMatchConvert
+		}
+		man, err := stringman.NewStringman(pref)
+		if err != nil {
+			loaded.err = err
+			return
+		}
+		loaded.man = man
+
+		loaded.columns = make(map[string][]stringman.ColumnBind)
+		for _, stmt := range man.Statements() {
+			loaded.columns[strings.ToUpper(stmt.Id)] = stmt.Columns()
+		}
+	})
+	return loaded.columns, loaded.err
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	columns, err := loadCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("fail to load statements : %s", err.Error())
+	}
+
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{(*ast.CallExpr)(nil)}
+	ins.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+
+		call := n.(*ast.CallExpr)
+		method, ok := stringmanMethod(pass, call)
+		if !ok {
+			return true
+		}
+
+		var id string
+		var paramArg int
+		switch method {
+		case "Build", "Format":
+			id, ok = implicitId(pass, call, method, stack)
+			if !ok {
+				return true
+			}
+			paramArg = 0
+		case "BuildWithStmt", "FormatWithStmt", "FormatNamed", "Render":
+			if len(call.Args) == 0 {
+				return true
+			}
+			id, ok = stringLiteral(call.Args[0])
+			if !ok {
+				return true
+			}
+			paramArg = 1
+		default:
+			return true
+		}
+
+		declared, exists := columns[strings.ToUpper(id)]
+		if !exists {
+			pass.Reportf(call.Pos(), "not found statement %s for %s", id, method)
+			return true
+		}
+
+		if (strings.HasPrefix(method, "Build") || method == "FormatNamed") && len(call.Args) > paramArg {
+			checkParamKeys(pass, call.Args[paramArg], id, declared, stack)
+		}
+		return true
+	})
+
+	return nil, nil
+}
+
+// stringmanMethod returns method name when call is a method call on stringman.StringMan
+func stringmanMethod(pass *analysis.Pass, call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok {
+		return "", false
+	}
+
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return "", false
+	}
+
+	recv := sig.Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	named, ok := recv.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return "", false
+	}
+	if named.Obj().Pkg().Path() != stringmanPath || named.Obj().Name() != stringmanType {
+		return "", false
+	}
+
+	return fn.Name(), true
+}
+
+// implicitId returns statement id derived from enclosing function like StringMan.Build does at runtime
+func implicitId(pass *analysis.Pass, call *ast.CallExpr, method string, stack []ast.Node) (string, bool) {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch f := stack[i].(type) {
+		case *ast.FuncLit:
+			if flagCaller == callerLegacy {
+				pass.Reportf(call.Pos(), "%s called inside function literal. statement id could not be resolved statically", method)
+				return "", false
+			}
+		case *ast.FuncDecl:
+			return declaredId(f), true
+		}
+	}
+	return "", false
+}
+
+func declaredId(f *ast.FuncDecl) string {
+	if f.Recv == nil || len(f.Recv.List) == 0 {
+		return f.Name.Name
+	}
+
+	var typeName string
+	switch flagCaller {
+	case callerTypeDot, callerTypeUnderscore:
+		typeName = receiverTypeName(f.Recv.List[0].Type)
+	}
+	if len(typeName) == 0 {
+		return f.Name.Name
+	}
+
+	if flagCaller == callerTypeDot {
+		return typeName + "." + f.Name.Name
+	}
+	return typeName + "_" + f.Name.Name
+}
+
+func receiverTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(e.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(e.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// checkParamKeys reports required columns missing in literal BuildParam keys.
+// parameter declared as composite literal or local variable filled with literal keys is checked
+func checkParamKeys(pass *analysis.Pass, arg ast.Expr, id string, declared []stringman.ColumnBind, stack []ast.Node) {
+	keys, ok := literalKeys(pass, arg, stack)
+	if !ok {
+		return
+	}
+
+	// parameter of nested path like {Order.Id} is reported by its root (Order)
+	reported := make(map[string]bool)
+	for _, c := range declared {
+		root := c.RootName()
+		if !c.IsRequired() || hasParamKey(keys, c.Name()) || hasParamKey(keys, root) || reported[root] {
+			continue
+		}
+		reported[root] = true
+		pass.Reportf(arg.Pos(), "missing parameter %s for statement %s", root, id)
+	}
+}
+
+// hasParamKey reports whether any key binds variable name by -match mode
+func hasParamKey(keys map[string]bool, name string) bool {
+	for k := range keys {
+		if loaded.man.MatchParamName(name, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func literalKeys(pass *analysis.Pass, arg ast.Expr, stack []ast.Node) (map[string]bool, bool) {
+	switch e := arg.(type) {
+	case *ast.CompositeLit:
+		return compositeKeys(e)
+	case *ast.Ident:
+		obj := pass.TypesInfo.Uses[e]
+		if obj == nil {
+			return nil, false
+		}
+		body := enclosingBody(stack)
+		if body == nil {
+			return nil, false
+		}
+		return variableKeys(pass, obj, body)
+	}
+	return nil, false
+}
+
+func compositeKeys(lit *ast.CompositeLit) (map[string]bool, bool) {
+	keys := make(map[string]bool)
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, false
+		}
+		key, ok := stringLiteral(kv.Key)
+		if !ok {
+			return nil, false
+		}
+		keys[key] = true
+	}
+	return keys, true
+}
+
+// variableKeys collects keys of local map variable. any dynamic usage gives up checking
+func variableKeys(pass *analysis.Pass, obj types.Object, body *ast.BlockStmt) (map[string]bool, bool) {
+	keys := make(map[string]bool)
+	static := true
+	initialized := false
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		if !static {
+			return false
+		}
+
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok {
+			return true
+		}
+
+		for i, lhs := range assign.Lhs {
+			switch l := lhs.(type) {
+			case *ast.Ident:
+				if pass.TypesInfo.ObjectOf(l) != obj || i >= len(assign.Rhs) {
+					continue
+				}
+				lit, ok := assign.Rhs[i].(*ast.CompositeLit)
+				if !ok {
+					if call, isCall := assign.Rhs[i].(*ast.CallExpr); isCall && isMake(call) {
+						initialized = true
+						continue
+					}
+					static = false
+					return false
+				}
+				litKeys, ok := compositeKeys(lit)
+				if !ok {
+					static = false
+					return false
+				}
+				for k := range litKeys {
+					keys[k] = true
+				}
+				initialized = true
+			case *ast.IndexExpr:
+				ident, ok := l.X.(*ast.Ident)
+				if !ok || pass.TypesInfo.ObjectOf(ident) != obj {
+					continue
+				}
+				key, ok := stringLiteral(l.Index)
+				if !ok {
+					static = false
+					return false
+				}
+				keys[key] = true
+			}
+		}
+		return true
+	})
+
+	return keys, static && initialized
+}
+
+func isMake(call *ast.CallExpr) bool {
+	ident, ok := call.Fun.(*ast.Ident)
+	return ok && ident.Name == "make"
+}
+
+func enclosingBody(stack []ast.Node) *ast.BlockStmt {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch f := stack[i].(type) {
+		case *ast.FuncLit:
+			return f.Body
+		case *ast.FuncDecl:
+			return f.Body
+		}
+	}
+	return nil
+}
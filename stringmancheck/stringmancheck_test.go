@@ -0,0 +1,62 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringmancheck
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	testdata := analysistest.TestData()
+	flagPath = testdata
+	flagFileset = "string*.xml"
+	flagCaller = callerLegacy
+	flagMatch = matchExact
+	loaded = catalog{}
+
+	analysistest.Run(t, testdata, Analyzer, "sample")
+}
+
+func TestAnalyzerTypeMethod(t *testing.T) {
+	testdata := analysistest.TestData()
+	flagPath = testdata
+	flagFileset = "string*.xml"
+	flagCaller = callerTypeDot
+	flagMatch = matchExact
+	loaded = catalog{}
+
+	analysistest.Run(t, testdata, Analyzer, "typemethod")
+}
+
+func TestAnalyzerMatchIgnoreCase(t *testing.T) {
+	testdata := analysistest.TestData()
+	flagPath = testdata
+	flagFileset = "string*.xml"
+	flagCaller = callerLegacy
+	flagMatch = matchIgnoreCase
+	loaded = catalog{}
+
+	analysistest.Run(t, testdata, Analyzer, "matchmode")
+}
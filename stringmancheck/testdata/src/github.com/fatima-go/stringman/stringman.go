@@ -0,0 +1,17 @@
+package stringman
+
+type BuildParam map[string]interface{}
+
+type StringMan struct{}
+
+func (man *StringMan) Build(param BuildParam) (string, error) { return "", nil }
+
+func (man *StringMan) BuildWithStmt(id string, param BuildParam) (string, error) { return "", nil }
+
+func (man *StringMan) Format(param ...interface{}) (string, error) { return "", nil }
+
+func (man *StringMan) FormatWithStmt(id string, param ...interface{}) (string, error) { return "", nil }
+
+func (man *StringMan) FormatNamed(id string, param map[string]interface{}) (string, error) {
+	return "", nil
+}
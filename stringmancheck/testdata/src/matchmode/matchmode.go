@@ -0,0 +1,21 @@
+package matchmode
+
+import "github.com/fatima-go/stringman"
+
+var man *stringman.StringMan
+
+func updateAlbum() {
+	man.BuildWithStmt("UpdateAlbum", stringman.BuildParam{"score": 1, "ID": 2})
+}
+
+func updateAlbumMissing() {
+	man.BuildWithStmt("UpdateAlbum", stringman.BuildParam{"score": 1}) // want `missing parameter Id for statement UpdateAlbum`
+}
+
+func insertOrder(order interface{}) {
+	man.BuildWithStmt("InsertOrder", stringman.BuildParam{"order": order})
+}
+
+func insertOrderMissing() {
+	man.BuildWithStmt("InsertOrder", stringman.BuildParam{"Id": 1}) // want `missing parameter Order for statement InsertOrder`
+}
@@ -0,0 +1,44 @@
+package sample
+
+import "github.com/fatima-go/stringman"
+
+var man *stringman.StringMan
+
+func UpdateAlbum() {
+	p := stringman.BuildParam{}
+	p["Score"] = 1
+	p["Id"] = 2
+	man.Build(p)
+}
+
+func updateAlbumMissing() {
+	man.BuildWithStmt("updateAlbum", stringman.BuildParam{"Id": 1}) // want `missing parameter Score for statement updateAlbum`
+}
+
+func updateAlbumDynamic(key string) {
+	p := stringman.BuildParam{}
+	p[key] = 1
+	man.BuildWithStmt("updateAlbum", p)
+}
+
+func RenamedFunction() {
+	man.Build(nil) // want `not found statement RenamedFunction for Build`
+}
+
+func completeFormatText() {
+	man.Format("fatima", 4)
+
+	func() {
+		man.Format("fatima", 4) // want `Format called inside function literal`
+	}()
+}
+
+type album struct{}
+
+func (a *album) UpdateAlbum() {
+	man.Build(stringman.BuildParam{"Score": 1, "Id": 2})
+}
+
+func notifyAlbum() {
+	man.FormatNamed("UpdateAlbum", map[string]interface{}{"Score": 1}) // want `missing parameter Id for statement UpdateAlbum`
+}
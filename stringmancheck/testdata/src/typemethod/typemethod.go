@@ -0,0 +1,17 @@
+package typemethod
+
+import "github.com/fatima-go/stringman"
+
+var man *stringman.StringMan
+
+type album struct{}
+
+func (a *album) FindAlbum() {
+	func() {
+		man.Build(stringman.BuildParam{"Id": 1})
+	}()
+}
+
+func (a album) UpdateAlbum() {
+	man.Build(stringman.BuildParam{"Id": 1}) // want `not found statement album.UpdateAlbum for Build`
+}
@@ -30,6 +30,7 @@ import (
 )
 
 const (
+	rawPrefixCharacter  = '$'
 	delimStartCharacter = '{'
 	delimStartString    = "{"
 	delimStopString     = "}"
@@ -98,38 +99,74 @@ func (n *UserQueryNormalizer) normalize(stmt *QueryStatement) error {
 		return fmt.Errorf("invalid query : %s", stmt.Query)
 	}
 
+	columns, holded, err := holdVariables(stmt.Query)
+	if err != nil {
+		return err
+	}
+
+	stmt.columnMention = columns
+	stmt.HoldedQuery = holded
+	stmt.Query = n.resolveHolding(stmt.HoldedQuery)
+	return nil
+}
+
+// holdVariables replaces variable declarations with holdByte and returns declared variables
+func holdVariables(query string) ([]ColumnBind, string, error) {
+	columns := make([]ColumnBind, 0)
 	var hold bytes.Buffer
 
-	queryLen := len(stmt.Query)
+	queryLen := len(query)
 	for i := 0; i < queryLen; i++ {
-		ch := stmt.Query[i]
+		ch := query[i]
+		raw := false
+		if ch == rawPrefixCharacter && i+1 < queryLen && query[i+1] == delimStartCharacter {
+			raw = true
+			i++
+			ch = delimStartCharacter
+		}
 		if ch != delimStartCharacter {
 			hold.WriteByte(ch)
 			continue
 		}
 
+		if stopIndex, ok := matchChoice(query, i); ok && !raw {
+			bind, err := parseChoiceBind(query[i+1:stopIndex], hold.Len()+1)
+			if err != nil {
+				return nil, "", fmt.Errorf("%s : %s", err.Error(), query)
+			}
+			columns = append(columns, bind)
+			i = stopIndex
+			hold.WriteByte(holdByte)
+			continue
+		}
+
 		if i >= queryLen-2 {
-			return fmt.Errorf("incompleted variable closer : %s", stmt.Query)
+			return nil, "", fmt.Errorf("incompleted variable closer : %s", query)
 		}
-		stopIndex := strings.Index(stmt.Query[i+1:], delimStopString)
+		stopIndex := strings.Index(query[i+1:], delimStopString)
 		if stopIndex < 1 {
-			return fmt.Errorf("incompleted variable closer : %s", stmt.Query)
+			return nil, "", fmt.Errorf("incompleted variable closer : %s", query)
 		}
 
-		v := stmt.Query[i+1 : i+1+stopIndex]
+		v := query[i+1 : i+1+stopIndex]
 		if strings.Index(v, delimStartString) >= 0 {
-			return fmt.Errorf("invalid variable declare format : %s", stmt.Query)
+			return nil, "", fmt.Errorf("invalid variable declare format : %s", query)
 		}
 
-		stmt.columnMention = append(stmt.columnMention, NewColumnBind(v, hold.Len()+1))
+		bind, err := parseColumnBind(v, hold.Len()+1)
+		if err != nil {
+			return nil, "", err
+		}
+		if raw {
+			bind.bindType = columnBindTypeRaw
+		}
+		columns = append(columns, bind)
 
 		i = i + stopIndex + 1
 		hold.WriteByte(holdByte)
 	}
 
-	stmt.HoldedQuery = hold.String()
-	stmt.Query = n.resolveHolding(stmt.HoldedQuery)
-	return nil
+	return columns, hold.String(), nil
 }
 
 func (n *UserQueryNormalizer) resolveHolding(query string) string {
@@ -0,0 +1,110 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+const engineTemplate = "template"
+
+// templateFuncs returns functions usable in template statement
+//
+//	sql   : literal with quoting. quote in value is escaped. {{sql .Name}} => 'fatima'
+//	list  : comma separated literals for IN clause. {{list .Ids}} => 1,2,3
+//	ident : identifier without quoting. {{ident .Table}} => ted_track
+//	text  : plain text without quoting
+func (l *statementLoader) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"sql":   l.encoders.asString,
+		"text":  l.encoders.asText,
+		"list":  l.templateList,
+		"ident": templateIdent,
+	}
+}
+
+func (l *statementLoader) templateList(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("list needs slice or array but %v", reflect.TypeOf(v))
+	}
+	if rv.Len() == 0 {
+		return "", fmt.Errorf("list needs at least one element")
+	}
+
+	literals := make([]string, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		str, err := l.encoders.asString(rv.Index(i).Interface())
+		if err != nil {
+			return "", err
+		}
+		literals[i] = str
+	}
+	return strings.Join(literals, ","), nil
+}
+
+func templateIdent(v interface{}) (string, error) {
+	str := fmt.Sprint(v)
+	if !identifierRegex.MatchString(str) {
+		return "", fmt.Errorf("not allowed identifier [%s]", str)
+	}
+	return str, nil
+}
+
+// compileTemplate compiles statement body with text/template at load time
+func (l *statementLoader) compileTemplate(stmt *QueryStatement) error {
+	stmt.Query = strings.Trim(stmt.Query, cutset)
+	stmt.columnMention = make([]ColumnBind, 0)
+
+	tmpl, err := template.New(stmt.Id).Funcs(l.templateFuncs()).Option("missingkey=error").Parse(stmt.Query)
+	if err != nil {
+		return fmt.Errorf("fail to compile template %s : %s", stmt.Id, err.Error())
+	}
+
+	stmt.tmpl = tmpl
+	return nil
+}
+
+// Render executes template statement (engine="template") with data
+func (man *StringMan) Render(stmtId string, data interface{}) (string, error) {
+	stmt, err := man.find(stmtId)
+	if err != nil {
+		return "", err
+	}
+
+	if !stmt.IsTemplate() {
+		return "", fmt.Errorf("%s is not template statement", stmtId)
+	}
+
+	var buffer bytes.Buffer
+	err = stmt.tmpl.Execute(&buffer, data)
+	if err != nil {
+		return "", fmt.Errorf("fail to render %s : %s", stmtId, err.Error())
+	}
+
+	return strings.Trim(buffer.String(), cutset), nil
+}
@@ -0,0 +1,411 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	canonicalXmlHeader = `<?xml version="1.0" encoding="UTF-8" ?>`
+	canonicalIndent    = "    "
+	cdataStart         = "<![CDATA["
+	cdataStop          = "]]>"
+)
+
+// xmlTextElement is a <text> or <fragment> element with comments declared right before it
+type xmlTextElement struct {
+	name     string
+	id       string
+	comments []string
+	attr     []xml.Attr
+	inner    []string // comments inside element
+	allows   []xmlAllowElement
+	body     string // <include> is kept as includeMarker
+}
+
+type xmlAllowElement struct {
+	param  string
+	values []string
+}
+
+type xmlDocument struct {
+	header   bool
+	prolog   []string
+	root     xml.StartElement
+	elements []xmlTextElement
+	trailing []string // comments after last element in root
+	epilog   []string
+}
+
+// CanonicalXml rewrites xml statement file with consistent indentation. comments are preserved and
+// CDATA is used only when text contains '<' or '&'. text elements are sorted by id when sortById is true.
+// statement text is kept as it is except leading and trailing spaces
+func CanonicalXml(data []byte, sortById bool) ([]byte, error) {
+	doc, err := readXmlDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if sortById {
+		sort.SliceStable(doc.elements, func(i, j int) bool {
+			return doc.elements[i].id < doc.elements[j].id
+		})
+	}
+
+	return doc.write()
+}
+
+// readXmlDocument reads tokens with RawToken so namespace prefix like xsi:noNamespaceSchemaLocation is
+// kept as it is written. Token resolves prefix to namespace url
+func readXmlDocument(data []byte) (xmlDocument, error) {
+	doc := xmlDocument{}
+	// RawToken does not check that start and end elements match
+	err := checkWellFormed(data)
+	if err != nil {
+		return doc, err
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	rootDone := false
+	for {
+		t, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return doc, err
+		}
+
+		switch t := t.(type) {
+		case xml.ProcInst:
+			doc.header = true
+		case xml.Comment:
+			if rootDone {
+				doc.epilog = append(doc.epilog, string(t))
+			} else {
+				doc.prolog = append(doc.prolog, string(t))
+			}
+		case xml.CharData:
+			if len(strings.Trim(string(t), cutset)) > 0 {
+				return doc, fmt.Errorf("unexpected text outside of root : %s", strings.Trim(string(t), cutset))
+			}
+		case xml.StartElement:
+			if rootDone {
+				return doc, fmt.Errorf("unexpected element %s after root", t.Name.Local)
+			}
+			doc.root = t.Copy()
+			err = doc.readRoot(dec)
+			if err != nil {
+				return doc, err
+			}
+			rootDone = true
+		}
+	}
+
+	if !rootDone {
+		return doc, fmt.Errorf("not found root element")
+	}
+	return doc, nil
+}
+
+func checkWellFormed(data []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// skipRawElement skips to end of element started by RawToken. Skip of decoder expects Token
+func skipRawElement(dec *xml.Decoder) error {
+	depth := 1
+	for depth > 0 {
+		t, err := dec.RawToken()
+		if err != nil {
+			return err
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return nil
+}
+
+func (doc *xmlDocument) readRoot(dec *xml.Decoder) error {
+	comments := make([]string, 0)
+	for {
+		t, err := dec.RawToken()
+		if err != nil {
+			return err
+		}
+
+		switch t := t.(type) {
+		case xml.Comment:
+			comments = append(comments, string(t))
+		case xml.CharData:
+			if len(strings.Trim(string(t), cutset)) > 0 {
+				return fmt.Errorf("unexpected text in %s : %s", doc.root.Name.Local, strings.Trim(string(t), cutset))
+			}
+		case xml.StartElement:
+			switch buildElementType(t.Name.Local) {
+			case eleTypeText, eleTypeFragment:
+			default:
+				return fmt.Errorf("unsupported element %s", t.Name.Local)
+			}
+			element, err := readXmlTextElement(dec, t)
+			if err != nil {
+				return err
+			}
+			element.comments = comments
+			comments = make([]string, 0)
+			doc.elements = append(doc.elements, element)
+		case xml.EndElement:
+			doc.trailing = comments
+			return nil
+		}
+	}
+}
+
+func readXmlTextElement(dec *xml.Decoder, start xml.StartElement) (xmlTextElement, error) {
+	element := xmlTextElement{name: start.Name.Local, id: getAttr(start.Attr, attrId), attr: start.Copy().Attr}
+	fragment := buildElementType(start.Name.Local) == eleTypeFragment
+	var body bytes.Buffer
+	for {
+		t, err := dec.RawToken()
+		if err != nil {
+			return element, err
+		}
+
+		switch t := t.(type) {
+		case xml.Comment:
+			element.inner = append(element.inner, string(t))
+		case xml.CharData:
+			body.Write(t)
+		case xml.StartElement:
+			eleType := buildElementType(t.Name.Local)
+			if fragment || (eleType != eleTypeAllow && eleType != eleTypeInclude) {
+				return element, fmt.Errorf("unsupported element %s in %s", t.Name.Local, element.id)
+			}
+			if eleType == eleTypeInclude {
+				body.WriteString(includeMarker(getAttr(t.Attr, attrRef)))
+				if err := skipRawElement(dec); err != nil {
+					return element, err
+				}
+				continue
+			}
+			allow, err := readXmlAllowElement(dec, getAttr(t.Attr, attrParam))
+			if err != nil {
+				return element, err
+			}
+			element.allows = append(element.allows, allow)
+		case xml.EndElement:
+			element.body = strings.Trim(body.String(), cutset)
+			return element, nil
+		}
+	}
+}
+
+func readXmlAllowElement(dec *xml.Decoder, param string) (xmlAllowElement, error) {
+	allow := xmlAllowElement{param: param}
+	var values string
+	for {
+		t, err := dec.RawToken()
+		if err != nil {
+			return allow, err
+		}
+
+		switch t := t.(type) {
+		case xml.CharData:
+			values = values + string(t)
+		case xml.StartElement:
+			return allow, fmt.Errorf("unsupported element %s in allow", t.Name.Local)
+		case xml.EndElement:
+			for _, v := range strings.Split(values, allowSeparator) {
+				v = strings.Trim(v, cutset)
+				if len(v) > 0 {
+					allow.values = append(allow.values, v)
+				}
+			}
+			return allow, nil
+		}
+	}
+}
+
+func (doc xmlDocument) write() ([]byte, error) {
+	var buffer bytes.Buffer
+	if doc.header {
+		buffer.WriteString(canonicalXmlHeader)
+		buffer.WriteByte('\n')
+	}
+	for _, c := range doc.prolog {
+		writeComment(&buffer, "", c)
+	}
+
+	buffer.WriteByte('<')
+	buffer.WriteString(xmlName(doc.root.Name))
+	writeAttrs(&buffer, doc.root.Attr)
+	buffer.WriteString(">\n")
+
+	for i, e := range doc.elements {
+		if i > 0 {
+			buffer.WriteByte('\n')
+		}
+		err := e.write(&buffer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range doc.trailing {
+		writeComment(&buffer, canonicalIndent, c)
+	}
+
+	buffer.WriteString("</")
+	buffer.WriteString(xmlName(doc.root.Name))
+	buffer.WriteString(">\n")
+
+	for _, c := range doc.epilog {
+		writeComment(&buffer, "", c)
+	}
+	return buffer.Bytes(), nil
+}
+
+func (e xmlTextElement) write(buffer *bytes.Buffer) error {
+	indent := canonicalIndent + canonicalIndent
+	for _, c := range e.comments {
+		writeComment(buffer, canonicalIndent, c)
+	}
+
+	buffer.WriteString(canonicalIndent)
+	buffer.WriteByte('<')
+	buffer.WriteString(e.name)
+	writeAttrs(buffer, canonicalAttrOrder(e.attr))
+	buffer.WriteString(">\n")
+
+	for _, c := range e.inner {
+		writeComment(buffer, indent, c)
+	}
+	for _, a := range e.allows {
+		buffer.WriteString(indent)
+		buffer.WriteString("<allow")
+		writeAttrs(buffer, []xml.Attr{{Name: xml.Name{Local: attrParam}, Value: a.param}})
+		buffer.WriteByte('>')
+		xml.EscapeText(buffer, []byte(strings.Join(a.values, allowSeparator+" ")))
+		buffer.WriteString("</allow>\n")
+	}
+
+	if len(e.body) > 0 {
+		buffer.WriteString(indent)
+		// odd parts are fragment names of <include>
+		for i, part := range strings.Split(e.body, "\x00") {
+			if i%2 == 1 {
+				buffer.WriteString("<include")
+				writeAttrs(buffer, []xml.Attr{{Name: xml.Name{Local: attrRef}, Value: part}})
+				buffer.WriteString("/>")
+				continue
+			}
+			err := e.writeText(buffer, part)
+			if err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('\n')
+	}
+
+	buffer.WriteString(canonicalIndent)
+	buffer.WriteString("</")
+	buffer.WriteString(e.name)
+	buffer.WriteString(">\n")
+	return nil
+}
+
+func (e xmlTextElement) writeText(buffer *bytes.Buffer, text string) error {
+	switch {
+	case !strings.ContainsAny(text, "<&"):
+		buffer.WriteString(text)
+	case strings.Contains(text, cdataStop):
+		return fmt.Errorf("text of %s contains %s", e.id, cdataStop)
+	default:
+		buffer.WriteString(cdataStart)
+		buffer.WriteString(text)
+		buffer.WriteString(cdataStop)
+	}
+	return nil
+}
+
+// canonicalAttrOrder places id, engine, lang and override first and keeps order of others
+func canonicalAttrOrder(attr []xml.Attr) []xml.Attr {
+	ordered := make([]xml.Attr, 0, len(attr))
+	known := []string{attrId, attrEngine, attrLang, attrOverride}
+	for _, name := range known {
+		for _, a := range attr {
+			if a.Name.Local == name {
+				ordered = append(ordered, a)
+			}
+		}
+	}
+	for _, a := range attr {
+		if !containsString(known, a.Name.Local) {
+			ordered = append(ordered, a)
+		}
+	}
+	return ordered
+}
+
+func writeAttrs(buffer *bytes.Buffer, attr []xml.Attr) {
+	for _, a := range attr {
+		buffer.WriteByte(' ')
+		buffer.WriteString(xmlName(a.Name))
+		buffer.WriteString(`="`)
+		xml.EscapeText(buffer, []byte(a.Value))
+		buffer.WriteByte('"')
+	}
+}
+
+// xmlName returns name with prefix read by RawToken. e.g) xsi:noNamespaceSchemaLocation
+func xmlName(name xml.Name) string {
+	if len(name.Space) > 0 {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+func writeComment(buffer *bytes.Buffer, indent string, comment string) {
+	buffer.WriteString(indent)
+	buffer.WriteString("<!--")
+	buffer.WriteString(comment)
+	buffer.WriteString("-->\n")
+}
@@ -0,0 +1,143 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ * @project stringman
+ * @author jin.freestyle@gmail.com
+ */
+
+package stringman
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var unformattedXml = []byte(`<?xml version='1.0' encoding='UTF-8'?>
+<!-- statements of city -->
+<query>
+  <!-- select by name -->
+	<text lang="en" id="SelectCity"><![CDATA[SELECT * FROM CITY WHERE NAME={Name}]]></text>
+<text id="SelectAge">
+		SELECT * FROM CITY
+		 WHERE AGE &lt; {Age}
+	<allow   param="Sort">name,age</allow>
+	</text>
+  <!-- end -->
+</query>
+`)
+
+var formattedXml = `<?xml version="1.0" encoding="UTF-8" ?>
+<!-- statements of city -->
+<query>
+    <!-- select by name -->
+    <text id="SelectCity" lang="en">
+        SELECT * FROM CITY WHERE NAME={Name}
+    </text>
+
+    <text id="SelectAge">
+        <allow param="Sort">name, age</allow>
+        <![CDATA[SELECT * FROM CITY
+		 WHERE AGE < {Age}]]>
+    </text>
+    <!-- end -->
+</query>
+`
+
+func TestCanonicalXml(t *testing.T) {
+	formatted, err := CanonicalXml(unformattedXml, false)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, formattedXml, string(formatted))
+
+	again, err := CanonicalXml(formatted, false)
+	assert.Nil(t, err)
+	assert.Equal(t, string(formatted), string(again))
+
+	before, err := parseWithSax(unformattedXml)
+	assert.Nil(t, err)
+	after, err := parseWithSax(formatted)
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+
+	sorted, err := CanonicalXml(unformattedXml, true)
+	assert.Nil(t, err)
+	before, _ = parseWithSax(sorted)
+	if assert.Equal(t, 2, len(before)) {
+		assert.Equal(t, "SelectAge", before[0].Id)
+	}
+
+	_, err = CanonicalXml([]byte(`<query><txt id="A">hello</txt></query>`), false)
+	assert.NotNil(t, err)
+}
+
+func TestCanonicalXmlFragment(t *testing.T) {
+	data := []byte(`<query><fragment id="cols">id, name</fragment>
+<text id="A">SELECT <include ref="cols" /> FROM CITY WHERE AGE &lt; {Age}</text></query>`)
+	expect := `<query>
+    <fragment id="cols">
+        id, name
+    </fragment>
+
+    <text id="A">
+        SELECT <include ref="cols"/><![CDATA[ FROM CITY WHERE AGE < {Age}]]>
+    </text>
+</query>
+`
+
+	formatted, err := CanonicalXml(data, false)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, expect, string(formatted))
+
+	before, err := parseWithSax(data)
+	assert.Nil(t, err)
+	after, err := parseWithSax(formatted)
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+	if assert.Equal(t, 1, len(after)) {
+		assert.Equal(t, "SELECT id, name FROM CITY WHERE AGE < {Age}", after[0].Query)
+	}
+}
+
+func TestCanonicalXmlNamespacePrefix(t *testing.T) {
+	data := []byte(`<query xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:noNamespaceSchemaLocation="s.xsd">
+<text id="A" xml:space="preserve">SELECT 1</text></query>`)
+	expect := `<query xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:noNamespaceSchemaLocation="s.xsd">
+    <text id="A" xml:space="preserve">
+        SELECT 1
+    </text>
+</query>
+`
+
+	formatted, err := CanonicalXml(data, false)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, expect, string(formatted))
+
+	again, err := CanonicalXml(formatted, false)
+	if assert.Nil(t, err) {
+		assert.Equal(t, expect, string(again))
+	}
+
+	_, err = CanonicalXml([]byte(`<query><text id="A">SELECT 1</query></text>`), false)
+	assert.NotNil(t, err)
+}